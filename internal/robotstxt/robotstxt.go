@@ -0,0 +1,239 @@
+// Package robotstxt parses, merges, and renders robots.txt documents. It
+// exists so the plugin can combine multiple sources into one coherent file
+// instead of concatenating their raw text.
+package robotstxt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Directive is a single robots.txt rule line, e.g. "Disallow: /private/".
+type Directive struct {
+	Name  string
+	Value string
+}
+
+// Group is one or more consecutive User-agent lines sharing the directives
+// that follow them.
+type Group struct {
+	Agents     []string
+	Directives []Directive
+}
+
+// Document is a fully parsed robots.txt: its User-agent groups plus the
+// directives that apply to the whole file rather than to one agent.
+type Document struct {
+	Groups     []Group
+	Sitemaps   []string
+	CrawlDelay string
+}
+
+// Parse splits content into a Document. Blank lines and comments end the
+// current run of User-agent lines, matching how real crawlers group
+// consecutive "User-agent:" declarations. Sitemap and Crawl-delay are
+// treated as top-level directives regardless of where in the file they
+// appear.
+//
+// When strict is true, Parse rejects a non-blank, non-comment line that
+// isn't a "key: value" pair, and rejects a directive line (other than
+// Sitemap or Crawl-delay) that appears before any User-agent line.
+func Parse(content string, strict bool) (Document, error) {
+	var doc Document
+	var current *Group
+	inAgentRun := false
+
+	for n, rawLine := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") {
+			inAgentRun = false
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			if strict {
+				return Document{}, fmt.Errorf("robotstxt: malformed line %d: %q", n+1, rawLine)
+			}
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch {
+		case strings.EqualFold(key, "User-agent"):
+			if !inAgentRun {
+				doc.Groups = append(doc.Groups, Group{})
+				current = &doc.Groups[len(doc.Groups)-1]
+				inAgentRun = true
+			}
+			current.Agents = append(current.Agents, value)
+			continue
+		case strings.EqualFold(key, "Sitemap"):
+			doc.Sitemaps = append(doc.Sitemaps, value)
+			inAgentRun = false
+			continue
+		case strings.EqualFold(key, "Crawl-delay"):
+			doc.CrawlDelay = value
+			inAgentRun = false
+			continue
+		}
+
+		inAgentRun = false
+		if current == nil {
+			if strict {
+				return Document{}, fmt.Errorf("robotstxt: directive %q on line %d has no preceding User-agent", key, n+1)
+			}
+			continue
+		}
+		current.Directives = append(current.Directives, Directive{Name: key, Value: value})
+	}
+
+	return doc, nil
+}
+
+// MergeStrategy controls how Merge combines same-agent groups from multiple
+// Documents.
+type MergeStrategy string
+
+const (
+	// Append keeps every directive from every input, in input order, with
+	// no deduplication.
+	Append MergeStrategy = "append"
+	// Dedupe collapses identical directives per agent, keeping first-seen
+	// order. This is the default strategy.
+	Dedupe MergeStrategy = "dedupe"
+	// StrictOverride behaves like Dedupe, except when two inputs define the
+	// same agent, the highest-Priority input's directives for that agent
+	// entirely replace the others' instead of being combined with them.
+	StrictOverride MergeStrategy = "strict-override"
+)
+
+// Input is one named, prioritized Document to merge. Priority only matters
+// under StrictOverride, where the highest-Priority input contributing to a
+// given agent wins that agent outright.
+type Input struct {
+	Name     string
+	Document Document
+	Priority int
+}
+
+// mergedAgent accumulates the directives chosen for one agent while Merge
+// walks its inputs in order.
+type mergedAgent struct {
+	agent            string
+	directives       []Directive
+	seenDirective    map[string]bool
+	hasOverride      bool
+	overridePriority int
+}
+
+func (m *mergedAgent) appendDeduped(directives []Directive) {
+	for _, d := range directives {
+		key := strings.ToLower(d.Name) + ":" + d.Value
+		if m.seenDirective[key] {
+			continue
+		}
+		m.seenDirective[key] = true
+		m.directives = append(m.directives, d)
+	}
+}
+
+// Merge combines inputs into a single Document according to strategy.
+// Agents are matched case-insensitively; the output keeps each agent's
+// first-seen casing and is sorted by agent name (case-insensitive) so
+// repeated Merge calls over the same inputs produce identical output.
+// Sitemaps are deduplicated and concatenated in input order; CrawlDelay is
+// taken from the first input that sets one.
+func Merge(inputs []Input, strategy MergeStrategy) Document {
+	byAgent := make(map[string]*mergedAgent)
+	var sitemaps []string
+	seenSitemap := make(map[string]bool)
+	var crawlDelay string
+
+	for _, in := range inputs {
+		if crawlDelay == "" && in.Document.CrawlDelay != "" {
+			crawlDelay = in.Document.CrawlDelay
+		}
+		for _, sm := range in.Document.Sitemaps {
+			if !seenSitemap[sm] {
+				seenSitemap[sm] = true
+				sitemaps = append(sitemaps, sm)
+			}
+		}
+
+		for _, group := range in.Document.Groups {
+			for _, agent := range group.Agents {
+				key := strings.ToLower(agent)
+				merged, ok := byAgent[key]
+				if !ok {
+					merged = &mergedAgent{agent: agent, seenDirective: make(map[string]bool)}
+					byAgent[key] = merged
+				}
+
+				switch strategy {
+				case Append:
+					merged.directives = append(merged.directives, group.Directives...)
+				case StrictOverride:
+					if !merged.hasOverride || in.Priority > merged.overridePriority {
+						merged.directives = nil
+						merged.seenDirective = make(map[string]bool)
+						merged.overridePriority = in.Priority
+						merged.hasOverride = true
+					} else if in.Priority < merged.overridePriority {
+						continue
+					}
+					merged.appendDeduped(group.Directives)
+				default: // Dedupe, and any unrecognized strategy
+					merged.appendDeduped(group.Directives)
+				}
+			}
+		}
+	}
+
+	agentKeys := make([]string, 0, len(byAgent))
+	for key := range byAgent {
+		agentKeys = append(agentKeys, key)
+	}
+	sort.Strings(agentKeys)
+
+	doc := Document{Sitemaps: sitemaps, CrawlDelay: crawlDelay}
+	for _, key := range agentKeys {
+		m := byAgent[key]
+		doc.Groups = append(doc.Groups, Group{Agents: []string{m.agent}, Directives: m.directives})
+	}
+	return doc
+}
+
+// Render serializes doc into robots.txt text: one blank-line-separated block
+// per group in doc.Groups order, followed by any Sitemap and Crawl-delay
+// lines.
+func Render(doc Document) string {
+	var b strings.Builder
+	for i, group := range doc.Groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, agent := range group.Agents {
+			b.WriteString("User-agent: " + agent + "\n")
+		}
+		for _, d := range group.Directives {
+			b.WriteString(d.Name + ": " + d.Value + "\n")
+		}
+	}
+
+	if len(doc.Sitemaps) > 0 {
+		if len(doc.Groups) > 0 {
+			b.WriteString("\n")
+		}
+		for _, sm := range doc.Sitemaps {
+			b.WriteString("Sitemap: " + sm + "\n")
+		}
+	}
+	if doc.CrawlDelay != "" {
+		b.WriteString("Crawl-delay: " + doc.CrawlDelay + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package robotstxt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hhftechnology/traefik-pangolin-robots-txt/internal/robotstxt"
+)
+
+func TestMergeDedupeCombinesDuplicateAgentGroups(t *testing.T) {
+	docA, err := robotstxt.Parse("User-agent: GPTBot\nDisallow: /\n", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docB, err := robotstxt.Parse("User-agent: GPTBot\nDisallow: /\n\nUser-agent: CCBot\nDisallow: /\n", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged := robotstxt.Merge([]robotstxt.Input{
+		{Name: "a", Document: docA},
+		{Name: "b", Document: docB},
+	}, robotstxt.Dedupe)
+
+	if len(merged.Groups) != 2 {
+		t.Fatalf("expected 2 merged agent groups, got %d", len(merged.Groups))
+	}
+
+	var gptbot *robotstxt.Group
+	for i, g := range merged.Groups {
+		if g.Agents[0] == "GPTBot" {
+			gptbot = &merged.Groups[i]
+		}
+	}
+	if gptbot == nil {
+		t.Fatal("expected a merged GPTBot group")
+	}
+	if len(gptbot.Directives) != 1 {
+		t.Errorf("expected the duplicate Disallow: / to collapse into one directive, got %d", len(gptbot.Directives))
+	}
+}
+
+func TestMergePreservesSitemaps(t *testing.T) {
+	docA, _ := robotstxt.Parse("Sitemap: https://a.example.com/sitemap.xml\nUser-agent: *\nDisallow: /admin/\n", false)
+	docB, _ := robotstxt.Parse("Sitemap: https://b.example.com/sitemap.xml\n", false)
+
+	merged := robotstxt.Merge([]robotstxt.Input{
+		{Name: "a", Document: docA},
+		{Name: "b", Document: docB},
+	}, robotstxt.Dedupe)
+
+	if len(merged.Sitemaps) != 2 {
+		t.Fatalf("expected both sitemaps to survive merging, got %v", merged.Sitemaps)
+	}
+
+	rendered := robotstxt.Render(merged)
+	if !strings.Contains(rendered, "Sitemap: https://a.example.com/sitemap.xml") ||
+		!strings.Contains(rendered, "Sitemap: https://b.example.com/sitemap.xml") {
+		t.Errorf("expected both Sitemap lines in rendered output, got: %s", rendered)
+	}
+}
+
+func TestMergeStrictOverrideHighestPriorityWins(t *testing.T) {
+	community, _ := robotstxt.Parse("User-agent: GPTBot\nDisallow: /\n", false)
+	override, _ := robotstxt.Parse("User-agent: GPTBot\nAllow: /\n", false)
+
+	merged := robotstxt.Merge([]robotstxt.Input{
+		{Name: "community", Document: community, Priority: 0},
+		{Name: "custom", Document: override, Priority: 10},
+	}, robotstxt.StrictOverride)
+
+	if len(merged.Groups) != 1 {
+		t.Fatalf("expected a single GPTBot group, got %d", len(merged.Groups))
+	}
+	directives := merged.Groups[0].Directives
+	if len(directives) != 1 || directives[0].Name != "Allow" {
+		t.Errorf("expected the higher-priority input's Allow to replace the lower-priority Disallow, got %v", directives)
+	}
+}
+
+func TestParseRejectsMalformedInputUnderStrictParsing(t *testing.T) {
+	malformed := "User-agent: GPTBot\nthis is not a directive\n"
+
+	if _, err := robotstxt.Parse(malformed, false); err != nil {
+		t.Errorf("expected non-strict parsing to tolerate a malformed line, got: %v", err)
+	}
+
+	if _, err := robotstxt.Parse(malformed, true); err == nil {
+		t.Error("expected strict parsing to reject a malformed line")
+	}
+
+	orphan := "Disallow: /\nUser-agent: GPTBot\n"
+	if _, err := robotstxt.Parse(orphan, true); err == nil {
+		t.Error("expected strict parsing to reject a directive with no preceding User-agent")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"embed"
 	"fmt"
 	"io"
 	"log"
@@ -12,72 +13,332 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/hhftechnology/traefik-pangolin-robots-txt/internal/robotstxt"
 )
 
+//go:embed presets/*.txt
+var presetFiles embed.FS
+
+// presetVersions lists the bundled rule presets available via Config.Presets,
+// keyed by name, mapped to the version stamped into their "# preset:" header.
+var presetVersions = map[string]string{
+	"ai-training":            "v2024-11",
+	"ai-search":              "v2024-11",
+	"seo-scrapers":           "v2024-11",
+	"archivers":              "v2024-11",
+	"vulnerability-scanners": "v2024-11",
+}
+
+// ListPresets returns the names of all bundled rule presets, sorted, for use
+// by tooling (e.g. validating a config or building a UI picker).
+func ListPresets() []string {
+	names := make([]string, 0, len(presetVersions))
+	for name := range presetVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isValidPreset reports whether name is a known bundled preset.
+func isValidPreset(name string) bool {
+	_, ok := presetVersions[name]
+	return ok
+}
+
+// presetContent reads the embedded robots.txt body for a bundled preset.
+func presetContent(name string) (string, error) {
+	data, err := presetFiles.ReadFile("presets/" + name + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to read preset %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
 // Config the plugin configuration.
 type Config struct {
 	// CustomRules contains custom robots.txt rules to append
 	CustomRules string `json:"customRules,omitempty"`
-	
+
 	// Overwrite determines if original robots.txt content should be replaced
 	Overwrite bool `json:"overwrite,omitempty"`
-	
+
 	// AiRobotsTxt enables fetching AI robots.txt rules from external source
 	AiRobotsTxt bool `json:"aiRobotsTxt,omitempty"`
-	
+
 	// LastModified controls whether to preserve Last-Modified headers
 	LastModified bool `json:"lastModified,omitempty"`
-	
+
 	// AiRobotsTxtURL allows custom URL for AI robots.txt source
 	// Defaults to GitHub repository if not specified
 	AiRobotsTxtURL string `json:"aiRobotsTxtUrl,omitempty"`
-	
+
 	// AiRobotsTxtPath allows specifying a local file path instead of URL
 	// Takes precedence over AiRobotsTxtURL if both are specified
 	AiRobotsTxtPath string `json:"aiRobotsTxtPath,omitempty"`
-	
+
+	// AiRobotsTxtSources merges multiple curated bot lists together, e.g. the
+	// community ai.robots.txt list plus a corporate blocklist and a local
+	// override. When set, it takes precedence over AiRobotsTxtURL/AiRobotsTxtPath,
+	// which remain supported as a shorthand for a single unnamed source.
+	AiRobotsTxtSources []SourceSpec `json:"aiRobotsTxtSources,omitempty"`
+
 	// CacheTTL specifies how long to cache external content (in seconds)
 	// Default: 300 seconds (5 minutes)
 	CacheTTL int `json:"cacheTtl,omitempty"`
-	
+
 	// MaxRetries specifies maximum retry attempts for external requests
 	// Default: 3
 	MaxRetries int `json:"maxRetries,omitempty"`
-	
+
 	// RequestTimeout specifies timeout for external HTTP requests (in seconds)
 	// Default: 10 seconds
 	RequestTimeout int `json:"requestTimeout,omitempty"`
-	
+
 	// FallbackContent provides content to use when external sources fail
 	FallbackContent string `json:"fallbackContent,omitempty"`
-	
+
 	// EnableMetrics enables detailed logging for monitoring
 	EnableMetrics bool `json:"enableMetrics,omitempty"`
+
+	// StaleTTL specifies how long expired content may still be served (in seconds)
+	// while a background refresh is in flight. Default: 60 seconds
+	StaleTTL int `json:"staleTtl,omitempty"`
+
+	// EnforceDisallow turns the composed Disallow rules into an active block:
+	// requests other than /robots.txt whose User-Agent matches a disallowed
+	// agent are short-circuited instead of merely being told not to crawl.
+	EnforceDisallow bool `json:"enforceDisallow,omitempty"`
+
+	// EnforceStatusCode is the status code returned for blocked requests.
+	// Default: 403
+	EnforceStatusCode int `json:"enforceStatusCode,omitempty"`
+
+	// EnforceExcludePaths lists request paths that are never blocked by
+	// EnforceDisallow, e.g. health checks, regardless of User-Agent.
+	EnforceExcludePaths []string `json:"enforceExcludePaths,omitempty"`
+
+	// BlockedUserAgentsExtra lists additional User-Agent matches to block
+	// under EnforceDisallow beyond whatever CustomRules/the fetched AI list
+	// disallow, e.g. a known bad actor not yet listed upstream. Entries match
+	// as a case-insensitive substring of the request's User-Agent header,
+	// unless prefixed with "regex:", in which case the remainder is compiled
+	// as a case-insensitive regular expression. A match blocks the request on
+	// every path, not just the ones a Disallow rule would otherwise cover.
+	BlockedUserAgentsExtra []string `json:"blockedUserAgentsExtra,omitempty"`
+
+	// MetricsPath, when set together with EnableMetrics, is intercepted by
+	// ServeHTTP and answered with Prometheus text-format metrics instead of
+	// being passed to next. Default: "/plugin/robots-txt/metrics".
+	MetricsPath string `json:"metricsPath,omitempty"`
+
+	// Presets selects bundled, embedded rule presets (see ListPresets) to
+	// include in the composed AI robots.txt content, e.g. ["ai-training"].
+	// They need no network fetch and no cache, and combine with
+	// AiRobotsTxtURL/AiRobotsTxtSources through the same merge/dedup pipeline.
+	Presets []string `json:"presets,omitempty"`
+
+	// MergeStrategy controls how sources/presets defining the same
+	// User-agent are combined: "dedupe" (default) collapses identical
+	// directives per agent; "append" keeps every directive from every
+	// source with no deduplication; "strict-override" lets the
+	// highest-Priority source win a shared agent outright instead of
+	// combining with lower-priority ones. See SourceSpec.Priority.
+	MergeStrategy string `json:"mergeStrategy,omitempty"`
+
+	// StrictParsing rejects malformed source/preset content (a directive
+	// line that isn't "key: value", or one with no preceding User-agent)
+	// instead of silently skipping the offending line. Content that fails
+	// strict parsing fails the whole composed AI robots.txt block, as if no
+	// source could be fetched (FallbackContent is used if set); unlike a
+	// single source's fetch error, it is not skipped in favor of the others.
+	StrictParsing bool `json:"strictParsing,omitempty"`
+
+	// MaxConcurrentFetches bounds how many AiRobotsTxtSources are fetched at
+	// once, both when composing a response and during background refresh, so
+	// a long list of sources can't open unbounded outbound connections.
+	// Default: 4
+	MaxConcurrentFetches int `json:"maxConcurrentFetches,omitempty"`
+
+	// Hosts overrides CustomRules/AiRobotsTxt/Overwrite/sources per request
+	// Host header, keyed by an exact hostname (e.g. "a.example.com") or a
+	// "*.example.com" wildcard. The longest matching pattern wins; a request
+	// whose Host matches nothing falls back to the top-level Config fields.
+	// Lets one Traefik instance front many tenants behind Pangolin with
+	// different robots.txt content per domain.
+	Hosts map[string]HostConfig `json:"hosts,omitempty"`
+
+	// FileWatchInterval controls how often local AiRobotsTxtPath/
+	// AiRobotsTxtSources files are polled for ModTime/Size changes (in
+	// seconds), so edits to a mounted ConfigMap or bind mount take effect
+	// without a Traefik restart. The Yaegi plugin sandbox blocks fsnotify, so
+	// this is a lightweight os.Stat poll rather than a real filesystem watch.
+	// Default: 30. 0 disables watching; the file is then only re-read on its
+	// normal CacheTTL expiry like before this option existed.
+	FileWatchInterval int `json:"fileWatchInterval,omitempty"`
 }
 
-// cacheEntry represents a cached robots.txt content with expiration
-type cacheEntry struct {
+// HostConfig overrides a subset of Config for requests whose Host header
+// matches its key in Config.Hosts. A matched HostConfig's fields are used
+// as they are: a field left at its zero value is not inherited from the
+// top-level Config, e.g. a HostConfig with only CustomRules set serves no
+// AI robots.txt sources, and one without CustomRules serves none of the
+// top-level CustomRules.
+type HostConfig struct {
+	// CustomRules replaces Config.CustomRules for this host.
+	CustomRules string `json:"customRules,omitempty"`
+
+	// Overwrite replaces Config.Overwrite for this host.
+	Overwrite bool `json:"overwrite,omitempty"`
+
+	// AiRobotsTxt replaces Config.AiRobotsTxt for this host.
+	AiRobotsTxt bool `json:"aiRobotsTxt,omitempty"`
+
+	// AiRobotsTxtURL is this host's single-source shorthand, as
+	// Config.AiRobotsTxtURL is for the top level.
+	AiRobotsTxtURL string `json:"aiRobotsTxtUrl,omitempty"`
+
+	// AiRobotsTxtPath is this host's single-source shorthand, as
+	// Config.AiRobotsTxtPath is for the top level.
+	AiRobotsTxtPath string `json:"aiRobotsTxtPath,omitempty"`
+
+	// AiRobotsTxtSources replaces Config.AiRobotsTxtSources for this host.
+	AiRobotsTxtSources []SourceSpec `json:"aiRobotsTxtSources,omitempty"`
+}
+
+// SourceSpec describes a single robots.txt source to merge into the composed
+// AI robots.txt block.
+type SourceSpec struct {
+	// Name labels this source in the "# source: <name>" comments emitted above
+	// each merged User-agent group. Defaults to the URL or Path if omitted.
+	Name string `json:"name,omitempty"`
+
+	// URL fetches the source over HTTP(S).
+	URL string `json:"url,omitempty"`
+
+	// Path reads the source from a local file. Takes precedence over URL.
+	Path string `json:"path,omitempty"`
+
+	// Weight is reserved for future ordering between overlapping sources.
+	Weight int `json:"weight,omitempty"`
+
+	// Priority determines which source wins when MergeStrategy is
+	// "strict-override" and two sources define the same User-agent: the
+	// highest Priority contributing to that agent replaces the others'
+	// directives for it entirely. Ignored by "append" and "dedupe". Default 0.
+	Priority int `json:"priority,omitempty"`
+
+	// Timeout overrides Config.RequestTimeout for this source alone (in
+	// seconds), e.g. to give a slow internal list more time than the
+	// community list it's merged with. Ignored for Path sources. Default:
+	// Config.RequestTimeout.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// resolvedSource is a SourceSpec after defaulting and validation, used
+// internally to key the cache and drive fetches.
+type resolvedSource struct {
+	name     string
+	url      string
+	path     string
+	priority int
+	timeout  time.Duration
+
+	// host is the Hosts pattern this source was resolved from, empty for the
+	// top-level Config's sources. It namespaces the cache key so a host
+	// override sharing a URL/path with another host (or the top level) still
+	// gets its own independent cache entry.
+	host string
+}
+
+// cacheKey returns the cache map key for this source: its file path if one is
+// configured, otherwise its URL, namespaced by host for a per-host override.
+func (rs resolvedSource) cacheKey() string {
+	key := rs.path
+	if key == "" {
+		key = rs.url
+	}
+	if rs.host != "" {
+		return rs.host + "|" + key
+	}
+	return key
+}
+
+// cacheSnapshot is one immutable version of a source's cached content. A
+// fetch never mutates an existing snapshot's fields; it builds a new one and
+// stores it, so a reader that loaded a snapshot before a concurrent refresh
+// always sees a internally-consistent content/expiresAt/etag/lastModified
+// tuple, never a torn mix of old and new.
+type cacheSnapshot struct {
 	content   string
 	expiresAt time.Time
-	source    string // URL or file path for debugging
+
+	// etag and lastModified are the validators returned by the upstream for
+	// URL-based sources, reused as If-None-Match / If-Modified-Since on the
+	// next fetch so an unchanged upstream costs a 304 instead of a full body.
+	etag         string
+	lastModified string
+}
+
+// cacheEntry represents a cached robots.txt content with expiration.
+// snapshot is read/written through an atomic.Value (see cacheSnapshot) so
+// fetchSourceWithCache's hot-path read never blocks on cacheMutex and never
+// observes a partially-written entry.
+type cacheEntry struct {
+	source   string       // URL or file path for debugging
+	snapshot atomic.Value // holds cacheSnapshot
+
+	// refreshMu guards refreshing and is used as a per-source single-flight lock
+	// so a stale read only ever triggers one background refetch.
+	refreshMu  sync.Mutex
+	refreshing bool
+}
+
+// load returns the entry's current cacheSnapshot, or the zero value and false
+// if nothing has been stored yet.
+func (e *cacheEntry) load() (cacheSnapshot, bool) {
+	snap, ok := e.snapshot.Load().(cacheSnapshot)
+	return snap, ok
+}
+
+// fileWatch tracks one local-file source's hot-reload state. lastModTime and
+// lastSize are only ever touched by fileWatchLoop's single goroutine, so they
+// need no lock; content is an atomic.Value so fetchSourceWithCache's read in
+// the ServeHTTP hot path never blocks on a mutex.
+type fileWatch struct {
+	path        string
+	lastModTime time.Time
+	lastSize    int64
+	content     atomic.Value // string
 }
 
 // CreateConfig creates the default plugin configuration.
 func CreateConfig() *Config {
 	return &Config{
-		CustomRules:     "",
-		Overwrite:       false,
-		AiRobotsTxt:     false,
-		LastModified:    false,
-		AiRobotsTxtURL:  "https://raw.githubusercontent.com/ai-robots-txt/ai.robots.txt/refs/heads/main/robots.txt",
-		CacheTTL:        300, // 5 minutes default
-		MaxRetries:      3,
-		RequestTimeout:  10,
-		FallbackContent: "",
-		EnableMetrics:   false,
+		CustomRules:          "",
+		Overwrite:            false,
+		AiRobotsTxt:          false,
+		LastModified:         false,
+		AiRobotsTxtURL:       "https://raw.githubusercontent.com/ai-robots-txt/ai.robots.txt/refs/heads/main/robots.txt",
+		CacheTTL:             300, // 5 minutes default
+		MaxRetries:           3,
+		RequestTimeout:       10,
+		FallbackContent:      "",
+		EnableMetrics:        false,
+		StaleTTL:             60, // serve stale content for up to 1 minute while refreshing
+		EnforceDisallow:      false,
+		EnforceStatusCode:    http.StatusForbidden,
+		MetricsPath:          "/plugin/robots-txt/metrics",
+		MaxConcurrentFetches: 4,
+		FileWatchInterval:    30,
 	}
 }
 
@@ -91,6 +352,10 @@ type responseWriter struct {
 	statusCode        int
 }
 
+// maxTrackedAgents bounds the number of distinct User-Agent values
+// incrementBlockedCount will track individually; see blockedByAgent.
+const maxTrackedAgents = 50
+
 // RobotsTxtPlugin a robots.txt plugin with enhanced caching and configuration.
 type RobotsTxtPlugin struct {
 	customRules     string
@@ -99,35 +364,154 @@ type RobotsTxtPlugin struct {
 	lastModified    bool
 	aiRobotsTxtURL  string
 	aiRobotsTxtPath string
+	sources         []resolvedSource
+	hostRoutes      []hostRoute // precompiled Config.Hosts matcher, exact hostnames first, then longest pattern first
+
+	// allSources is sources plus every hostRoute's sources, deduplicated by
+	// cache key, used only to drive refreshLoop's background refresh.
+	allSources []resolvedSource
+
+	// fileWatches holds one entry per local-file source (keyed by cacheKey),
+	// kept warm by fileWatchLoop so fetchSourceWithCache can serve them
+	// without ever touching cacheMutex. Empty when FileWatchInterval is 0.
+	fileWatches map[string]*fileWatch
+
+	// fileWatchInterval is how often fileWatchLoop polls fileWatches. Zero
+	// means file watching is disabled and fileWatchLoop is never started.
+	fileWatchInterval time.Duration
+
+	presets         []string
+	mergeStrategy   robotstxt.MergeStrategy
+	strictParsing   bool
 	cacheTTL        time.Duration
+	staleTTL        time.Duration
 	maxRetries      int
 	requestTimeout  time.Duration
 	fallbackContent string
 	enableMetrics   bool
 	next            http.Handler
-	
+
+	enforceDisallow      bool
+	enforceStatusCode    int
+	enforceExcludePaths  []string
+	blockedAgentMatchers []blockedAgentMatcher
+	metricsPath          string
+
 	// Cache for external content with mutex for thread safety
 	cache      map[string]*cacheEntry
 	cacheMutex sync.RWMutex
-	
-	// HTTP client with timeout for external requests
+
+	// HTTP client for external requests. Per-request timeouts come from the
+	// request context (see resolvedSource.timeout) rather than a client-wide
+	// Timeout, so different sources can use different RequestTimeout overrides.
 	httpClient *http.Client
-	
+
+	// fetchSem bounds how many sources are fetched concurrently, sized by
+	// MaxConcurrentFetches. Acquired before each source fetch in
+	// fetchAiRobotsTxtWithCache and refreshLoop.
+	fetchSem chan struct{}
+
+	// enforcementStates holds the parsed Disallow/Allow rules used by
+	// enforcement mode, one entry per effective config (keyed by configKey,
+	// see effectiveConfig; "" is the top-level Config). isBlocked only ever
+	// reads it, so a non-/robots.txt request never fetches or merges a
+	// source; the content is instead (re)composed by ServeHTTP whenever a
+	// /robots.txt request is served, and by refreshBackgroundEnforcement
+	// after each background source refresh, so it stays close to current
+	// without costing every proxied request a fetch.
+	enforcementStates sync.Map // configKey string -> enforcementState
+
 	// Metrics counters
-	cacheHits      int64
-	cacheMisses    int64
-	externalCalls  int64
-	errors         int64
-	metricsMutex   sync.RWMutex
+	cacheHits     int64
+	cacheMisses   int64
+	externalCalls int64
+	errors        int64
+	blocked       int64
+	served        int64
+	fetchSuccess  int64
+	fetchError    int64
+	fetchFallback int64
+	metricsMutex  sync.RWMutex
+
+	// perSource holds cache_hits/cache_misses/external_calls broken out by
+	// source (URL or file path), for the per-source Prometheus labels.
+	perSource map[string]*sourceMetrics
+
+	// blockedByAgent holds the blocked-request count per User-Agent, for the
+	// robotstxt_blocked_total{user_agent="..."} Prometheus label. Capped at
+	// maxTrackedAgents distinct values so a scraper can't grow this map (and
+	// the Prometheus endpoint's cardinality) without bound by varying its
+	// User-Agent on every blocked request; anything past the cap is folded
+	// into blockedOther instead of a new map entry.
+	blockedByAgent map[string]int64
+
+	// blockedOther counts blocked requests whose User-Agent wasn't already
+	// tracked in blockedByAgent once maxTrackedAgents distinct values had
+	// been seen, reported under the user_agent="other" label.
+	blockedOther int64
+
+	// fetchDuration is the histogram of fetchFromURL call durations, exposed
+	// as robotstxt_fetch_duration_seconds.
+	fetchDuration histogram
+}
+
+// sourceMetrics accumulates the per-source counters exposed with a
+// source="<url|path>" label on the Prometheus endpoint.
+type sourceMetrics struct {
+	cacheHits     int64
+	cacheMisses   int64
+	externalCalls int64
+}
+
+// histogram is a hand-rolled Prometheus-style cumulative histogram: bucket i
+// counts observations <= bounds[i], plus the implicit +Inf bucket (count).
+type histogram struct {
+	bounds []float64
+	counts []int64
+	sum    float64
+	count  int64
+}
+
+// defaultFetchDurationBounds are the upper bounds (in seconds) of the
+// robotstxt_fetch_duration_seconds histogram buckets.
+var defaultFetchDurationBounds = []float64{0.1, 0.3, 1.2, 5}
+
+func newHistogram(bounds []float64) histogram {
+	return histogram{bounds: bounds, counts: make([]int64, len(bounds))}
+}
+
+func (h *histogram) observe(seconds float64) {
+	for i, bound := range h.bounds {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
 }
 
 // New creates a new enhanced RobotsTxt plugin with caching and improved configuration.
 func New(ctx context.Context, next http.Handler, config *Config, name string) (http.Handler, error) {
 	// Validate configuration
-	if len(config.CustomRules) == 0 && !config.AiRobotsTxt {
-		return nil, fmt.Errorf("set customRules or set aiRobotsTxt to true")
+	if len(config.CustomRules) == 0 && !config.AiRobotsTxt && len(config.Presets) == 0 {
+		return nil, fmt.Errorf("set customRules, set aiRobotsTxt to true, or set presets")
+	}
+
+	for _, presetName := range config.Presets {
+		if !isValidPreset(presetName) {
+			return nil, fmt.Errorf("unknown preset %q, available presets: %s", presetName, strings.Join(ListPresets(), ", "))
+		}
+	}
+
+	mergeStrategy := robotstxt.MergeStrategy(config.MergeStrategy)
+	switch mergeStrategy {
+	case "":
+		mergeStrategy = robotstxt.Dedupe
+	case robotstxt.Append, robotstxt.Dedupe, robotstxt.StrictOverride:
+	default:
+		return nil, fmt.Errorf("unknown mergeStrategy %q, must be one of: append, dedupe, strict-override", config.MergeStrategy)
 	}
-	
+
 	// Set defaults for optional configuration
 	if config.CacheTTL <= 0 {
 		config.CacheTTL = 300 // 5 minutes
@@ -138,54 +522,439 @@ func New(ctx context.Context, next http.Handler, config *Config, name string) (h
 	if config.RequestTimeout <= 0 {
 		config.RequestTimeout = 10
 	}
-	if config.AiRobotsTxtURL == "" {
+	if config.StaleTTL < 0 {
+		config.StaleTTL = 60
+	}
+	if config.EnforceStatusCode <= 0 {
+		config.EnforceStatusCode = http.StatusForbidden
+	}
+	if config.MaxConcurrentFetches <= 0 {
+		config.MaxConcurrentFetches = 4
+	}
+	if config.FileWatchInterval < 0 {
+		config.FileWatchInterval = 30
+	}
+	if config.AiRobotsTxt && config.AiRobotsTxtURL == "" && config.AiRobotsTxtPath == "" && len(config.AiRobotsTxtSources) == 0 {
 		config.AiRobotsTxtURL = "https://raw.githubusercontent.com/ai-robots-txt/ai.robots.txt/refs/heads/main/robots.txt"
 	}
-	
-	// Validate file path if specified
-	if config.AiRobotsTxtPath != "" {
-		if !filepath.IsAbs(config.AiRobotsTxtPath) {
-			return nil, fmt.Errorf("aiRobotsTxtPath must be an absolute path: %s", config.AiRobotsTxtPath)
-		}
-		if _, err := os.Stat(config.AiRobotsTxtPath); os.IsNotExist(err) {
-			log.Printf("Warning: aiRobotsTxtPath does not exist: %s", config.AiRobotsTxtPath)
-		}
+
+	sources, err := buildSources(config)
+	if err != nil {
+		return nil, err
+	}
+
+	hostRoutes, err := buildHostRoutes(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileWatches map[string]*fileWatch
+	if config.FileWatchInterval > 0 {
+		fileWatches = buildFileWatches(dedupedSources(sources, hostRoutes))
 	}
 
 	plugin := &RobotsTxtPlugin{
-		customRules:     config.CustomRules,
-		overwrite:       config.Overwrite,
-		aiRobotsTxt:     config.AiRobotsTxt,
-		lastModified:    config.LastModified,
-		aiRobotsTxtURL:  config.AiRobotsTxtURL,
-		aiRobotsTxtPath: config.AiRobotsTxtPath,
-		cacheTTL:        time.Duration(config.CacheTTL) * time.Second,
-		maxRetries:      config.MaxRetries,
-		requestTimeout:  time.Duration(config.RequestTimeout) * time.Second,
-		fallbackContent: config.FallbackContent,
-		enableMetrics:   config.EnableMetrics,
-		next:            next,
-		cache:           make(map[string]*cacheEntry),
-		httpClient: &http.Client{
-			Timeout: time.Duration(config.RequestTimeout) * time.Second,
-		},
-	}
-	
+		customRules:       config.CustomRules,
+		overwrite:         config.Overwrite,
+		aiRobotsTxt:       config.AiRobotsTxt,
+		lastModified:      config.LastModified,
+		aiRobotsTxtURL:    config.AiRobotsTxtURL,
+		aiRobotsTxtPath:   config.AiRobotsTxtPath,
+		sources:           sources,
+		hostRoutes:        hostRoutes,
+		allSources:        dedupedSources(sources, hostRoutes),
+		fileWatches:       fileWatches,
+		fileWatchInterval: time.Duration(config.FileWatchInterval) * time.Second,
+		presets:           config.Presets,
+		mergeStrategy:     mergeStrategy,
+		strictParsing:     config.StrictParsing,
+		cacheTTL:          time.Duration(config.CacheTTL) * time.Second,
+		staleTTL:          time.Duration(config.StaleTTL) * time.Second,
+		maxRetries:        config.MaxRetries,
+		requestTimeout:    time.Duration(config.RequestTimeout) * time.Second,
+		fallbackContent:   config.FallbackContent,
+		enableMetrics:     config.EnableMetrics,
+		next:              next,
+
+		enforceDisallow:      config.EnforceDisallow,
+		enforceStatusCode:    config.EnforceStatusCode,
+		enforceExcludePaths:  config.EnforceExcludePaths,
+		blockedAgentMatchers: buildBlockedAgentMatchers(config.BlockedUserAgentsExtra),
+		metricsPath:          config.MetricsPath,
+
+		cache:          make(map[string]*cacheEntry),
+		httpClient:     &http.Client{},
+		fetchSem:       make(chan struct{}, config.MaxConcurrentFetches),
+		perSource:      make(map[string]*sourceMetrics),
+		blockedByAgent: make(map[string]int64),
+		fetchDuration:  newHistogram(defaultFetchDurationBounds),
+	}
+
 	if config.EnableMetrics {
 		log.Printf("RobotsTxt plugin initialized with metrics enabled (cache TTL: %v)", plugin.cacheTTL)
 	}
 
+	// Populate every configKey's enforcement table once, synchronously,
+	// before New returns: without this, every configKey starts with no
+	// enforcementStates entry, and the first concurrent batch of proxied
+	// requests (which can arrive well before refreshLoop's first tick at
+	// CacheTTL/2) would otherwise each take enforcementTableFor's cold-start
+	// path. One blocking fetch here at startup is preferable to that.
+	plugin.refreshBackgroundEnforcement()
+
+	if len(plugin.allSources) > 0 {
+		go plugin.refreshLoop(ctx)
+	}
+
+	for _, fw := range plugin.fileWatches {
+		plugin.pollFileWatch(fw)
+	}
+	if plugin.fileWatchInterval > 0 && len(plugin.fileWatches) > 0 {
+		go plugin.fileWatchLoop(ctx)
+	}
+
 	return plugin, nil
 }
 
+// buildFileWatches returns one fileWatch per distinct local-file source in
+// sources (keyed by cacheKey, so a path shared across hosts is only polled
+// once), ready for an initial pollFileWatch before the plugin starts serving.
+func buildFileWatches(sources []resolvedSource) map[string]*fileWatch {
+	watches := make(map[string]*fileWatch)
+	for _, rs := range sources {
+		if rs.path == "" {
+			continue
+		}
+		key := rs.cacheKey()
+		if _, exists := watches[key]; exists {
+			continue
+		}
+		watches[key] = &fileWatch{path: rs.path}
+	}
+	return watches
+}
+
+// fileWatchLoop polls every entry in p.fileWatches every p.fileWatchInterval
+// for a changed ModTime or Size, re-reading and caching changed files so a
+// mounted ConfigMap or bind mount update takes effect without a Traefik
+// restart. It exits once ctx is canceled.
+func (p *RobotsTxtPlugin) fileWatchLoop(ctx context.Context) {
+	ticker := time.NewTicker(p.fileWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, fw := range p.fileWatches {
+				p.pollFileWatch(fw)
+			}
+		}
+	}
+}
+
+// pollFileWatch os.Stats fw.path and, if its ModTime or Size changed since
+// the last poll, re-reads the file and stores the new content in fw.content.
+// A stat or read error is logged and leaves the last-known-good content (if
+// any) in place rather than clearing it.
+func (p *RobotsTxtPlugin) pollFileWatch(fw *fileWatch) {
+	info, err := os.Stat(fw.path)
+	if err != nil {
+		if p.enableMetrics {
+			log.Printf("file watch: unable to stat %s: %v", fw.path, err)
+		}
+		return
+	}
+
+	if fw.content.Load() != nil && info.ModTime().Equal(fw.lastModTime) && info.Size() == fw.lastSize {
+		return
+	}
+
+	content, err := os.ReadFile(fw.path)
+	if err != nil {
+		log.Printf("file watch: unable to read %s: %v", fw.path, err)
+		return
+	}
+
+	fw.content.Store(string(content))
+	fw.lastModTime = info.ModTime()
+	fw.lastSize = info.Size()
+	if p.enableMetrics {
+		log.Printf("file watch: reloaded %s", fw.path)
+	}
+}
+
+// buildSources resolves the top-level Config's AiRobotsTxtSources (or the
+// legacy single AiRobotsTxtURL/AiRobotsTxtPath fields as a one-source
+// shorthand) into validated resolvedSources.
+func buildSources(config *Config) ([]resolvedSource, error) {
+	specs := config.AiRobotsTxtSources
+	if len(specs) == 0 && config.AiRobotsTxt && (config.AiRobotsTxtURL != "" || config.AiRobotsTxtPath != "") {
+		specs = []SourceSpec{{Name: "default", URL: config.AiRobotsTxtURL, Path: config.AiRobotsTxtPath}}
+	}
+	return resolveSourceSpecs(specs, config, "", "aiRobotsTxtSources")
+}
+
+// resolveSourceSpecs validates specs into resolvedSources, defaulting each
+// source's name and timeout from config. host labels the Hosts pattern these
+// sources belong to (empty for the top level) and fieldPath names the field
+// being resolved, for error messages.
+func resolveSourceSpecs(specs []SourceSpec, config *Config, host, fieldPath string) ([]resolvedSource, error) {
+	sources := make([]resolvedSource, 0, len(specs))
+	for i, spec := range specs {
+		if spec.Path != "" {
+			if !filepath.IsAbs(spec.Path) {
+				return nil, fmt.Errorf("%s[%d].path must be an absolute path: %s", fieldPath, i, spec.Path)
+			}
+			if _, err := os.Stat(spec.Path); os.IsNotExist(err) {
+				log.Printf("Warning: %s[%d].path does not exist: %s", fieldPath, i, spec.Path)
+			}
+		}
+
+		name := spec.Name
+		if name == "" {
+			if spec.Path != "" {
+				name = spec.Path
+			} else {
+				name = spec.URL
+			}
+		}
+
+		var timeout time.Duration
+		if spec.Timeout > 0 {
+			timeout = time.Duration(spec.Timeout) * time.Second
+		} else {
+			timeout = time.Duration(config.RequestTimeout) * time.Second
+		}
+
+		sources = append(sources, resolvedSource{name: name, url: spec.URL, path: spec.Path, priority: spec.Priority, timeout: timeout, host: host})
+	}
+
+	return sources, nil
+}
+
+// hostResolved is a HostConfig after defaulting and source resolution, ready
+// to drive a request matched to it.
+type hostResolved struct {
+	customRules string
+	overwrite   bool
+	aiRobotsTxt bool
+	sources     []resolvedSource
+}
+
+// hostRoute is one precompiled Config.Hosts entry: either an exact hostname
+// or, when isWildcard, a "*.example.com" pattern matched by suffix.
+type hostRoute struct {
+	pattern    string
+	isWildcard bool
+	suffix     string
+	resolved   *hostResolved
+}
+
+// buildHostRoutes resolves Config.Hosts into a matcher sorted with exact
+// hostnames before wildcards and, within each kind, by pattern length
+// descending, so matchHostConfig's first-match-wins linear scan
+// naturally prefers a more specific pattern (e.g. "a.example.com") over a
+// wildcard that also matches it (e.g. "*.example.com").
+func buildHostRoutes(config *Config) ([]hostRoute, error) {
+	routes := make([]hostRoute, 0, len(config.Hosts))
+	for pattern, hc := range config.Hosts {
+		specs := hc.AiRobotsTxtSources
+		if len(specs) == 0 && hc.AiRobotsTxt && (hc.AiRobotsTxtURL != "" || hc.AiRobotsTxtPath != "") {
+			specs = []SourceSpec{{Name: "default", URL: hc.AiRobotsTxtURL, Path: hc.AiRobotsTxtPath}}
+		}
+
+		lower := strings.ToLower(pattern)
+		sources, err := resolveSourceSpecs(specs, config, lower, fmt.Sprintf("hosts[%s].aiRobotsTxtSources", pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		route := hostRoute{
+			pattern: lower,
+			resolved: &hostResolved{
+				customRules: hc.CustomRules,
+				overwrite:   hc.Overwrite,
+				aiRobotsTxt: hc.AiRobotsTxt,
+				sources:     sources,
+			},
+		}
+		if suffix, ok := strings.CutPrefix(lower, "*"); ok {
+			route.isWildcard = true
+			route.suffix = suffix
+		}
+		routes = append(routes, route)
+	}
+
+	// Exact hostnames always outrank wildcards, regardless of string length
+	// ("a.example.com" must win over "*.example.com" even though they're the
+	// same length); within the same kind, the longer (more specific) pattern
+	// wins.
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].isWildcard != routes[j].isWildcard {
+			return !routes[i].isWildcard
+		}
+		return len(routes[i].pattern) > len(routes[j].pattern)
+	})
+	return routes, nil
+}
+
+// matchHostConfig returns the matching Config.Hosts pattern (used elsewhere
+// as a configKey, see effectiveConfig) and its resolved config for the first
+// (longest, see buildHostRoutes) pattern matching host, or ("", nil) if none
+// match, in which case callers should fall back to the top-level Config
+// fields. host is matched case-insensitively with any ":port" suffix stripped.
+func (p *RobotsTxtPlugin) matchHostConfig(host string) (string, *hostResolved) {
+	host = strings.ToLower(host)
+	if i := strings.IndexByte(host, ':'); i >= 0 {
+		host = host[:i]
+	}
+
+	for _, route := range p.hostRoutes {
+		if route.isWildcard {
+			if strings.HasSuffix(host, route.suffix) && len(host) > len(route.suffix) {
+				return route.pattern, route.resolved
+			}
+			continue
+		}
+		if host == route.pattern {
+			return route.pattern, route.resolved
+		}
+	}
+	return "", nil
+}
+
+// effectiveConfig returns the CustomRules/Overwrite/AiRobotsTxt/sources that
+// apply to a request for host: its matching Config.Hosts override if any,
+// otherwise the top-level Config. configKey identifies which of the two it
+// returned (the matched Hosts pattern, or "" for the top level), for callers
+// that cache per-config state such as enforcementStates.
+func (p *RobotsTxtPlugin) effectiveConfig(host string) (customRules string, overwrite bool, aiEnabled bool, sources []resolvedSource, configKey string) {
+	configKey, hr := p.matchHostConfig(host)
+	if hr != nil {
+		return hr.customRules, hr.overwrite, hr.aiRobotsTxt, hr.sources, configKey
+	}
+	return p.customRules, p.overwrite, p.aiRobotsTxt, p.sources, configKey
+}
+
+// dedupedSources combines the top-level sources with every hostRoute's
+// sources into the flat list refreshLoop keeps warm in the background,
+// deduplicated by cache key since two hosts (or a host and the top level)
+// commonly share the same upstream.
+func dedupedSources(sources []resolvedSource, hostRoutes []hostRoute) []resolvedSource {
+	seen := make(map[string]bool, len(sources))
+	all := make([]resolvedSource, 0, len(sources))
+
+	add := func(rs resolvedSource) {
+		key := rs.cacheKey()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		all = append(all, rs)
+	}
+
+	for _, rs := range sources {
+		add(rs)
+	}
+	for _, route := range hostRoutes {
+		for _, rs := range route.resolved.sources {
+			add(rs)
+		}
+	}
+	return all
+}
+
+// refreshLoop periodically refreshes each registered AI robots.txt source in
+// the background so that TTL expiry never stalls an in-flight request. It
+// exits once ctx is canceled, which happens when Traefik tears down the plugin.
+func (p *RobotsTxtPlugin) refreshLoop(ctx context.Context) {
+	interval := p.cacheTTL / 2
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var wg sync.WaitGroup
+			for _, rs := range p.allSources {
+				rs := rs
+				wg.Add(1)
+				p.fetchSem <- struct{}{}
+				go func() {
+					defer wg.Done()
+					defer func() { <-p.fetchSem }()
+					if _, err := p.fetchAndCache(rs); err != nil && p.enableMetrics {
+						log.Printf("background refresh failed for %s: %v", rs.cacheKey(), err)
+					}
+				}()
+			}
+			wg.Wait()
+			p.refreshBackgroundEnforcement()
+		}
+	}
+}
+
+// refreshBackgroundEnforcement recomposes and caches the enforcement table
+// for every known configKey (the top-level Config plus each Config.Hosts
+// entry), so isBlocked's hot-path read in enforcementTableFor stays close to
+// current without ever fetching a source itself. Called once synchronously
+// by New to populate every configKey before the plugin starts serving, and
+// again after every refreshLoop tick refreshes the underlying sources. A
+// no-op unless EnforceDisallow is on.
+func (p *RobotsTxtPlugin) refreshBackgroundEnforcement() {
+	if !p.enforceDisallow {
+		return
+	}
+
+	content, err := p.composedRulesContent(p.customRules, p.aiRobotsTxt, p.sources)
+	if err != nil {
+		log.Printf("unable to compose rules for background enforcement refresh: %v", err)
+	}
+	p.updateEnforcementState("", content)
+
+	for _, route := range p.hostRoutes {
+		hr := route.resolved
+		content, err := p.composedRulesContent(hr.customRules, hr.aiRobotsTxt, hr.sources)
+		if err != nil {
+			log.Printf("unable to compose rules for background enforcement refresh of host %s: %v", route.pattern, err)
+		}
+		p.updateEnforcementState(route.pattern, content)
+	}
+}
+
 func (p *RobotsTxtPlugin) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if p.enableMetrics && p.metricsPath != "" && req.URL.Path == p.metricsPath {
+		p.servePrometheusMetrics(rw)
+		return
+	}
+
 	if strings.ToLower(req.URL.Path) != "/robots.txt" {
+		if p.enforceDisallow && p.isBlocked(req) {
+			p.incrementBlockedCount(req.Header.Get("User-Agent"))
+			if p.enableMetrics {
+				log.Printf("blocked %s from User-Agent %q", req.URL.Path, req.Header.Get("User-Agent"))
+			}
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			rw.WriteHeader(p.enforceStatusCode)
+			_, _ = rw.Write([]byte("blocked: disallowed by robots.txt enforcement\n"))
+			return
+		}
 		p.next.ServeHTTP(rw, req)
 		return
 	}
 
 	startTime := time.Now()
-	
+
+	customRules, overwrite, aiEnabled, sources, configKey := p.effectiveConfig(req.Host)
+
 	wrappedWriter := &responseWriter{
 		lastModified:      p.lastModified,
 		ResponseWriter:    rw,
@@ -201,7 +970,7 @@ func (p *RobotsTxtPlugin) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	var body string
 
 	// Include original content unless overwrite is enabled or backend returned 404
-	if !p.overwrite && wrappedWriter.backendStatusCode != http.StatusNotFound {
+	if !overwrite && wrappedWriter.backendStatusCode != http.StatusNotFound {
 		body = wrappedWriter.buffer.String() + "\n"
 	}
 
@@ -209,128 +978,690 @@ func (p *RobotsTxtPlugin) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	body += "# The following content was added on the fly by the Pangolin Robots.txt Traefik plugin: " +
 		"https://github.com/hhftechnology/traefik-pangolin-robots-txt\n"
 
-	// Fetch and append AI robots.txt content if enabled
-	if p.aiRobotsTxt {
-		aiRobotsTxt, err := p.fetchAiRobotsTxtWithCache()
+	// Fetch and append AI robots.txt content if enabled, or if bundled
+	// presets were requested (which need no separate enable flag)
+	var aiRobotsTxt string
+	if aiEnabled || len(p.presets) > 0 {
+		var err error
+		aiRobotsTxt, err = p.fetchAiRobotsTxtWithCache(sources)
 		if err != nil {
 			p.incrementErrorCount()
 			log.Printf("unable to fetch ai.robots.txt: %v", err)
-			
+
 			// Use fallback content if available
 			if p.fallbackContent != "" {
 				log.Printf("using fallback content for ai.robots.txt")
 				aiRobotsTxt = p.fallbackContent
+				p.incrementFetchResult("fallback")
+			} else {
+				p.incrementFetchResult("error")
 			}
+		} else {
+			p.incrementFetchResult("success")
 		}
 		body += aiRobotsTxt
 	}
-	
+
 	// Append custom rules
-	body += p.customRules
+	body += customRules
+
+	// Enforcement reuses the content just composed for this response, rather
+	// than enforcementTableFor fetching and merging sources again on every
+	// proxied request (see refreshBackgroundEnforcement for the other writer).
+	if p.enforceDisallow {
+		content := customRules
+		if aiEnabled || len(p.presets) > 0 {
+			content += "\n" + aiRobotsTxt
+		}
+		p.updateEnforcementState(configKey, content)
+	}
 
 	// Write the final response
 	_, err := rw.Write([]byte(body))
 	if err != nil {
 		p.incrementErrorCount()
 		log.Printf("unable to write body: %v", err)
+	} else {
+		p.incrementServedCount()
 	}
-	
+
 	// Log metrics if enabled
 	if p.enableMetrics {
 		duration := time.Since(startTime)
-		log.Printf("RobotsTxt request completed in %v (backend status: %d)", 
+		log.Printf("RobotsTxt request completed in %v (backend status: %d)",
 			duration, wrappedWriter.backendStatusCode)
 	}
 }
 
-// fetchAiRobotsTxtWithCache fetches AI robots.txt content with intelligent caching
-func (p *RobotsTxtPlugin) fetchAiRobotsTxtWithCache() (string, error) {
-	source := p.aiRobotsTxtPath
-	if source == "" {
-		source = p.aiRobotsTxtURL
+// fetchAiRobotsTxtWithCache fetches every source in sources (each with its
+// own stale-while-revalidate cache, see fetchSourceWithCache) plus any
+// configured presets, and merges the results into a single robots.txt block
+// per MergeStrategy (see mergeRobotsTxtSources). A lone source with no
+// presets is returned as fetched, without merging or deduplication. sources
+// is the top-level Config.AiRobotsTxtSources or a
+// matched HostConfig's, per effectiveConfig.
+func (p *RobotsTxtPlugin) fetchAiRobotsTxtWithCache(sources []resolvedSource) (string, error) {
+	if len(sources) == 0 && len(p.presets) == 0 {
+		return "", fmt.Errorf("no AI robots.txt sources or presets configured")
 	}
-	
-	// Check cache first
-	p.cacheMutex.RLock()
-	if entry, exists := p.cache[source]; exists && time.Now().Before(entry.expiresAt) {
-		p.cacheMutex.RUnlock()
-		p.incrementCacheHit()
-		if p.enableMetrics {
-			log.Printf("Cache hit for source: %s", source)
+
+	if len(sources) == 1 && len(p.presets) == 0 {
+		content, err := p.fetchSourceWithCache(sources[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch AI robots.txt from %s: %w", sources[0].cacheKey(), err)
 		}
-		return entry.content, nil
+		if p.strictParsing {
+			if _, err := robotstxt.Parse(content, true); err != nil {
+				return "", fmt.Errorf("malformed robots.txt content from %s: %w", sources[0].cacheKey(), err)
+			}
+		}
+		return content, nil
+	}
+
+	type sourceResult struct {
+		name     string
+		content  string
+		priority int
+		err      error
 	}
+
+	results := make([]sourceResult, len(sources))
+	var wg sync.WaitGroup
+	for i, rs := range sources {
+		wg.Add(1)
+		p.fetchSem <- struct{}{}
+		go func(i int, rs resolvedSource) {
+			defer wg.Done()
+			defer func() { <-p.fetchSem }()
+			content, err := p.fetchSourceWithCache(rs)
+			results[i] = sourceResult{name: rs.name, content: content, priority: rs.priority, err: err}
+		}(i, rs)
+	}
+	wg.Wait()
+
+	inputs := make([]mergeInput, 0, len(results)+len(p.presets))
+	var firstErr error
+	for _, r := range results {
+		if r.err != nil {
+			p.incrementErrorCount()
+			log.Printf("unable to fetch AI robots.txt source %s: %v", r.name, r.err)
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		inputs = append(inputs, mergeInput{name: r.name, content: r.content, priority: r.priority})
+	}
+
+	for _, name := range p.presets {
+		content, err := presetContent(name)
+		if err != nil {
+			p.incrementErrorCount()
+			log.Printf("unable to read preset %s: %v", name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		inputs = append(inputs, mergeInput{
+			presetLabel: fmt.Sprintf("%s (%s)", name, presetVersions[name]),
+			content:     content,
+		})
+	}
+
+	if len(inputs) == 0 {
+		return "", fmt.Errorf("all AI robots.txt sources and presets failed: %w", firstErr)
+	}
+
+	merged, err := mergeRobotsTxtSources(inputs, p.mergeStrategy, p.strictParsing)
+	if err != nil {
+		p.incrementErrorCount()
+		return "", err
+	}
+	return merged, nil
+}
+
+// fetchSourceWithCache fetches a single source with intelligent caching.
+// Reads always return the last known good content immediately: a fresh entry
+// is returned as-is, an expired-but-not-yet-stale entry is returned while a
+// single background refresh is kicked off, and only once the entry is older
+// than StaleTTL do we block on a synchronous refetch.
+func (p *RobotsTxtPlugin) fetchSourceWithCache(rs resolvedSource) (string, error) {
+	key := rs.cacheKey()
+
+	if fw, ok := p.fileWatches[key]; ok {
+		if content, ok := fw.content.Load().(string); ok {
+			return content, nil
+		}
+	}
+
+	p.cacheMutex.RLock()
+	entry, exists := p.cache[key]
 	p.cacheMutex.RUnlock()
-	
-	p.incrementCacheMiss()
+
+	if exists {
+		if snap, ok := entry.load(); ok {
+			now := time.Now()
+			if now.Before(snap.expiresAt) {
+				p.incrementCacheHit(key)
+				if p.enableMetrics {
+					log.Printf("Cache hit for source: %s", key)
+				}
+				return snap.content, nil
+			}
+
+			if now.Before(snap.expiresAt.Add(p.staleTTL)) {
+				p.incrementCacheHit(key)
+				log.Printf("Warning: serving stale content for %s while refreshing in background", key)
+				p.triggerAsyncRefresh(rs)
+				return snap.content, nil
+			}
+		}
+	}
+
+	p.incrementCacheMiss(key)
 	if p.enableMetrics {
-		log.Printf("Cache miss for source: %s", source)
-	}
-	
-	// Fetch fresh content
-	var content string
-	var err error
-	
-	if p.aiRobotsTxtPath != "" {
-		content, err = p.fetchFromFile(p.aiRobotsTxtPath)
-	} else {
-		content, err = p.fetchFromURL(p.aiRobotsTxtURL)
+		log.Printf("Cache miss for source: %s", key)
 	}
-	
+
+	content, err := p.fetchAndCache(rs)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch AI robots.txt from %s: %w", source, err)
+		// A source that has previously succeeded keeps serving its last known
+		// good body indefinitely on fetch failure, even once it's older than
+		// CacheTTL+StaleTTL; only a source that has never succeeded surfaces
+		// its error, so the caller can fall back to FallbackContent.
+		p.cacheMutex.RLock()
+		stale, ok := p.cache[key]
+		p.cacheMutex.RUnlock()
+		if ok {
+			if snap, ok := stale.load(); ok {
+				log.Printf("Warning: serving previously cached content for %s after fetch failure: %v", key, err)
+				return snap.content, nil
+			}
+		}
+		return "", err
+	}
+	return content, nil
+}
+
+// triggerAsyncRefresh kicks off a single background refetch of rs, using
+// entry.refreshing as a per-source single-flight guard so concurrent stale
+// reads don't pile up redundant fetches.
+func (p *RobotsTxtPlugin) triggerAsyncRefresh(rs resolvedSource) {
+	p.cacheMutex.RLock()
+	entry, exists := p.cache[rs.cacheKey()]
+	p.cacheMutex.RUnlock()
+	if !exists {
+		return
+	}
+
+	entry.refreshMu.Lock()
+	if entry.refreshing {
+		entry.refreshMu.Unlock()
+		return
+	}
+	entry.refreshing = true
+	entry.refreshMu.Unlock()
+
+	go func() {
+		defer func() {
+			entry.refreshMu.Lock()
+			entry.refreshing = false
+			entry.refreshMu.Unlock()
+		}()
+
+		if _, err := p.fetchAndCache(rs); err != nil {
+			log.Printf("background refresh failed for %s: %v", rs.cacheKey(), err)
+		}
+	}()
+}
+
+// fetchAndCache performs a synchronous fetch of rs and stores the result in
+// the cache, preserving the existing entry's single-flight lock if present.
+// For URL sources it sends the previous ETag/Last-Modified as conditional
+// headers; a 304 response bumps expiresAt in place without touching content.
+func (p *RobotsTxtPlugin) fetchAndCache(rs resolvedSource) (string, error) {
+	key := rs.cacheKey()
+
+	p.cacheMutex.RLock()
+	entry, exists := p.cache[key]
+	p.cacheMutex.RUnlock()
+
+	var priorSnap cacheSnapshot
+	if exists {
+		priorSnap, _ = entry.load()
+	}
+
+	var content, newETag, newLastModified string
+
+	if rs.path != "" {
+		var err error
+		content, err = p.fetchFromFile(rs.path)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		result, err := p.fetchFromURL(rs.url, priorSnap.etag, priorSnap.lastModified, rs.timeout)
+		if err != nil {
+			return "", err
+		}
+
+		if result.notModified {
+			if !exists {
+				return "", fmt.Errorf("received 304 Not Modified for %s with no cached content", rs.url)
+			}
+			refreshed := priorSnap
+			refreshed.expiresAt = time.Now().Add(p.cacheTTL)
+			entry.snapshot.Store(refreshed)
+
+			if p.enableMetrics {
+				log.Printf("Upstream reported not modified for %s, reusing cached content", key)
+			}
+			return refreshed.content, nil
+		}
+
+		content = result.content
+		newETag = result.etag
+		newLastModified = result.lastModified
 	}
-	
+
 	// Validate content
 	if err := p.validateRobotsContent(content); err != nil {
 		log.Printf("Warning: fetched robots.txt content validation failed: %v", err)
 	}
-	
-	// Cache the content
+
 	p.cacheMutex.Lock()
-	p.cache[source] = &cacheEntry{
-		content:   content,
-		expiresAt: time.Now().Add(p.cacheTTL),
-		source:    source,
+	entry, exists = p.cache[key]
+	if !exists {
+		entry = &cacheEntry{source: key}
+		p.cache[key] = entry
 	}
 	p.cacheMutex.Unlock()
-	
+
+	entry.snapshot.Store(cacheSnapshot{
+		content:      content,
+		expiresAt:    time.Now().Add(p.cacheTTL),
+		etag:         newETag,
+		lastModified: newLastModified,
+	})
+
 	if p.enableMetrics {
-		log.Printf("Cached content from %s (TTL: %v)", source, p.cacheTTL)
+		log.Printf("Cached content from %s (TTL: %v)", key, p.cacheTTL)
 	}
-	
+
 	return content, nil
 }
 
+// mergeInput is one fetched source ready to be merged. A regular fetched
+// source is labeled with name, which shows up in the emitted "# source:"
+// comment; a bundled preset is labeled with presetLabel instead (e.g.
+// "ai-training (v2024-11)"), which shows up in its own "# preset:" comment.
+// priority only matters under MergeStrategy "strict-override" (see
+// SourceSpec.Priority); presets are always priority 0.
+type mergeInput struct {
+	name        string
+	presetLabel string
+	content     string
+	priority    int
+}
+
+// agentAttribution tracks which sources/presets contributed to a merged
+// User-agent, for the "# source:"/"# preset:" comments. It's kept separate
+// from robotstxt.Merge's own bookkeeping because attribution always lists
+// every contributor, regardless of which one wins under strict-override.
+type agentAttribution struct {
+	sources    []string
+	seenSource map[string]bool
+	presets    []string
+	seenPreset map[string]bool
+}
+
+// mergeRobotsTxtSources parses each input with the internal/robotstxt
+// package and merges them per strategy into a single normalized robots.txt
+// block, where each merged group is preceded by a "# preset: <name
+// (version)>" comment per contributing preset and a "# source: <name>"
+// comment listing every fetched source that contributed to it.
+func mergeRobotsTxtSources(inputs []mergeInput, strategy robotstxt.MergeStrategy, strict bool) (string, error) {
+	attributions := make(map[string]*agentAttribution)
+	robotInputs := make([]robotstxt.Input, 0, len(inputs))
+
+	for _, in := range inputs {
+		doc, err := robotstxt.Parse(in.content, strict)
+		if err != nil {
+			label := in.name
+			if in.presetLabel != "" {
+				label = in.presetLabel
+			}
+			return "", fmt.Errorf("malformed robots.txt content from %s: %w", label, err)
+		}
+		robotInputs = append(robotInputs, robotstxt.Input{Name: in.name, Document: doc, Priority: in.priority})
+
+		for _, group := range doc.Groups {
+			for _, agent := range group.Agents {
+				key := strings.ToLower(agent)
+				attr, ok := attributions[key]
+				if !ok {
+					attr = &agentAttribution{seenSource: make(map[string]bool), seenPreset: make(map[string]bool)}
+					attributions[key] = attr
+				}
+
+				if in.presetLabel != "" {
+					if !attr.seenPreset[in.presetLabel] {
+						attr.seenPreset[in.presetLabel] = true
+						attr.presets = append(attr.presets, in.presetLabel)
+					}
+				} else if !attr.seenSource[in.name] {
+					attr.seenSource[in.name] = true
+					attr.sources = append(attr.sources, in.name)
+				}
+			}
+		}
+	}
+
+	merged := robotstxt.Merge(robotInputs, strategy)
+
+	var b strings.Builder
+	for i, group := range merged.Groups {
+		agent := group.Agents[0]
+		attr := attributions[strings.ToLower(agent)]
+
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, preset := range attr.presets {
+			b.WriteString("# preset: " + preset + "\n")
+		}
+		if len(attr.sources) > 0 {
+			b.WriteString("# source: " + strings.Join(attr.sources, ", ") + "\n")
+		}
+		b.WriteString("User-agent: " + agent + "\n")
+		for _, d := range group.Directives {
+			b.WriteString(d.Name + ": " + d.Value + "\n")
+		}
+	}
+
+	if len(merged.Sitemaps) > 0 {
+		if len(merged.Groups) > 0 {
+			b.WriteString("\n")
+		}
+		for _, sm := range merged.Sitemaps {
+			b.WriteString("Sitemap: " + sm + "\n")
+		}
+	}
+	if merged.CrawlDelay != "" {
+		b.WriteString("Crawl-delay: " + merged.CrawlDelay + "\n")
+	}
+
+	return b.String(), nil
+}
+
+// pathRule is a single Allow/Disallow path pattern from an enforcement group.
+type pathRule struct {
+	pattern string
+	allow   bool
+}
+
+// enforcementRule binds one User-agent token (lowercased, matched against the
+// request's User-Agent header by substring) to the path rules that apply to it.
+type enforcementRule struct {
+	agentToken string
+	rules      []pathRule
+}
+
+// buildEnforcementTable parses content (the composed CustomRules + AI robots.txt
+// body) into enforcement rules, one per User-agent per group. Only Allow/Disallow
+// directives are kept; everything else (Sitemap, Crawl-delay, comments) is
+// irrelevant to blocking and dropped.
+func buildEnforcementTable(content string) []enforcementRule {
+	var table []enforcementRule
+
+	doc, err := robotstxt.Parse(content, false)
+	if err != nil {
+		return table
+	}
+
+	for _, group := range doc.Groups {
+		var rules []pathRule
+		for _, d := range group.Directives {
+			switch strings.ToLower(d.Name) {
+			case "disallow":
+				if d.Value != "" {
+					rules = append(rules, pathRule{pattern: d.Value, allow: false})
+				}
+			case "allow":
+				if d.Value != "" {
+					rules = append(rules, pathRule{pattern: d.Value, allow: true})
+				}
+			}
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		for _, agent := range group.Agents {
+			table = append(table, enforcementRule{agentToken: strings.ToLower(agent), rules: rules})
+		}
+	}
+
+	return table
+}
+
+// matchesDisallow reports whether userAgent is blocked from path by table: the
+// agent token must appear as a substring of userAgent (or be "*", matching
+// everyone), and among that agent's rules the longest matching pattern wins,
+// so a more specific Allow can override a broader Disallow.
+func matchesDisallow(table []enforcementRule, userAgent, path string) bool {
+	ua := strings.ToLower(userAgent)
+
+	var winner *pathRule
+	winnerLen := -1
+	for _, entry := range table {
+		if entry.agentToken != "*" && !strings.Contains(ua, entry.agentToken) {
+			continue
+		}
+		for i, r := range entry.rules {
+			if !strings.HasPrefix(path, r.pattern) {
+				continue
+			}
+			if len(r.pattern) > winnerLen {
+				winnerLen = len(r.pattern)
+				winner = &entry.rules[i]
+			}
+		}
+	}
+
+	return winner != nil && !winner.allow
+}
+
+// isBlocked reports whether req should be rejected under enforcement mode: its
+// path isn't excluded, it carries a User-Agent, and that agent is either in
+// BlockedUserAgentsExtra or disallowed from the requested path by the
+// currently composed rules.
+func (p *RobotsTxtPlugin) isBlocked(req *http.Request) bool {
+	path := req.URL.Path
+	for _, excluded := range p.enforceExcludePaths {
+		if path == excluded {
+			return false
+		}
+	}
+
+	userAgent := req.Header.Get("User-Agent")
+	if userAgent == "" {
+		return false
+	}
+
+	if matchesBlockedAgentExtra(p.blockedAgentMatchers, userAgent) {
+		return true
+	}
+
+	_, _, _, _, configKey := p.effectiveConfig(req.Host)
+	return matchesDisallow(p.enforcementTableFor(configKey), userAgent, path)
+}
+
+// blockedAgentMatcher is one compiled entry from Config.BlockedUserAgentsExtra:
+// either a lowercased literal to substring-match, or a compiled regex.
+type blockedAgentMatcher struct {
+	literal string
+	regex   *regexp.Regexp
+}
+
+// buildBlockedAgentMatchers compiles Config.BlockedUserAgentsExtra once at
+// plugin construction time. A "regex:" prefix selects regex matching;
+// anything else is matched as a case-insensitive substring. Invalid regexes
+// are logged and skipped rather than failing plugin construction.
+func buildBlockedAgentMatchers(extra []string) []blockedAgentMatcher {
+	var matchers []blockedAgentMatcher
+	for _, raw := range extra {
+		if pattern, ok := strings.CutPrefix(raw, "regex:"); ok {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				log.Printf("invalid blockedUserAgentsExtra regex %q: %v", pattern, err)
+				continue
+			}
+			matchers = append(matchers, blockedAgentMatcher{regex: re})
+			continue
+		}
+		matchers = append(matchers, blockedAgentMatcher{literal: strings.ToLower(raw)})
+	}
+	return matchers
+}
+
+// matchesBlockedAgentExtra reports whether userAgent matches any compiled
+// BlockedUserAgentsExtra entry.
+func matchesBlockedAgentExtra(matchers []blockedAgentMatcher, userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, m := range matchers {
+		if m.regex != nil {
+			if m.regex.MatchString(userAgent) {
+				return true
+			}
+			continue
+		}
+		if m.literal != "" && strings.Contains(ua, m.literal) {
+			return true
+		}
+	}
+	return false
+}
+
+// enforcementState is the parsed enforcement table together with the composed
+// rule content it was built from, so staleness is a cheap string comparison.
+type enforcementState struct {
+	content string
+	table   []enforcementRule
+}
+
+// enforcementTableFor returns the parsed enforcement table for configKey
+// (see effectiveConfig). It is isBlocked's hot path, called on every
+// non-/robots.txt request once EnforceDisallow is on, so it never fetches or
+// merges a source itself: New populates p.enforcementStates for every
+// configKey before it returns, ServeHTTP refreshes it whenever a /robots.txt
+// request is served, and refreshBackgroundEnforcement refreshes it after
+// each background source refresh (see all three). If none of those has run
+// yet for this configKey, enforcement fails open (an empty table, so
+// Disallow rules don't block) rather than blocking the request goroutine on
+// a synchronous fetch.
+func (p *RobotsTxtPlugin) enforcementTableFor(configKey string) []enforcementRule {
+	if cached, ok := p.enforcementStates.Load(configKey); ok {
+		return cached.(enforcementState).table
+	}
+	return nil
+}
+
+// updateEnforcementState rebuilds and stores configKey's enforcement table
+// from content if content differs from what's already cached, skipping the
+// rebuild when nothing changed.
+func (p *RobotsTxtPlugin) updateEnforcementState(configKey, content string) {
+	if cached, ok := p.enforcementStates.Load(configKey); ok && cached.(enforcementState).content == content {
+		return
+	}
+	p.enforcementStates.Store(configKey, enforcementState{content: content, table: buildEnforcementTable(content)})
+}
+
+// composedRulesContent assembles the same rule content ServeHTTP appends to
+// /robots.txt for a given customRules/aiEnabled/sources (CustomRules plus the
+// fetched AI robots.txt and/or bundled presets, falling back to
+// FallbackContent on fetch failure) so enforcement always matches what's served.
+func (p *RobotsTxtPlugin) composedRulesContent(customRules string, aiEnabled bool, sources []resolvedSource) (string, error) {
+	var b strings.Builder
+	b.WriteString(customRules)
+
+	if aiEnabled || len(p.presets) > 0 {
+		aiRobotsTxt, err := p.fetchAiRobotsTxtWithCache(sources)
+		if err != nil {
+			if p.fallbackContent == "" {
+				return b.String(), err
+			}
+			aiRobotsTxt = p.fallbackContent
+		}
+		b.WriteString("\n")
+		b.WriteString(aiRobotsTxt)
+	}
+
+	return b.String(), nil
+}
+
 // fetchFromFile reads robots.txt content from a local file
 func (p *RobotsTxtPlugin) fetchFromFile(filePath string) (string, error) {
 	if p.enableMetrics {
 		log.Printf("Reading robots.txt from file: %s", filePath)
 	}
-	
+
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
-	
+
 	return string(content), nil
 }
 
-// fetchFromURL fetches robots.txt content from a URL with retry logic
-func (p *RobotsTxtPlugin) fetchFromURL(url string) (string, error) {
-	p.incrementExternalCall()
-	
+// urlFetchResult is the outcome of a single fetchFromURL call: either fresh
+// content plus the validators to remember for the next conditional request,
+// or notModified if the upstream confirmed the cached content is still current.
+type urlFetchResult struct {
+	content      string
+	etag         string
+	lastModified string
+	notModified  bool
+}
+
+// fetchFromURL fetches robots.txt content from a URL with retry logic. When
+// etag or lastModified are non-empty they are sent as If-None-Match /
+// If-Modified-Since so an unchanged upstream can respond 304 instead of
+// re-sending the full body. Each attempt is bounded by timeout, which lets a
+// source's SourceSpec.Timeout differ from other sources sharing this plugin.
+func (p *RobotsTxtPlugin) fetchFromURL(url, etag, lastModified string, timeout time.Duration) (urlFetchResult, error) {
+	p.incrementExternalCall(url)
+
+	start := time.Now()
+	defer func() {
+		p.observeFetchDuration(time.Since(start).Seconds())
+	}()
+
 	var lastErr error
 	for attempt := 1; attempt <= p.maxRetries; attempt++ {
 		if p.enableMetrics && attempt > 1 {
 			log.Printf("Retry attempt %d/%d for URL: %s", attempt, p.maxRetries, url)
 		}
-		
-		resp, err := p.httpClient.Get(url)
+
+		reqCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+		if err != nil {
+			cancel()
+			return urlFetchResult{}, fmt.Errorf("failed to build request for %s: %w", url, err)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+
+		resp, err := p.httpClient.Do(req)
+		cancel()
 		if err != nil {
 			lastErr = fmt.Errorf("HTTP request failed (attempt %d): %w", attempt, err)
 			if attempt < p.maxRetries {
-				time.Sleep(time.Duration(attempt) * time.Second) 
+				time.Sleep(time.Duration(attempt) * time.Second)
 				continue
 			}
 			break
@@ -342,6 +1673,13 @@ func (p *RobotsTxtPlugin) fetchFromURL(url string) (string, error) {
 			}
 		}()
 
+		if resp.StatusCode == http.StatusNotModified {
+			if p.enableMetrics {
+				log.Printf("Received 304 Not Modified for %s", url)
+			}
+			return urlFetchResult{notModified: true}, nil
+		}
+
 		if resp.StatusCode != http.StatusOK {
 			lastErr = fmt.Errorf("HTTP status code %d (attempt %d)", resp.StatusCode, attempt)
 			if attempt < p.maxRetries && resp.StatusCode >= 500 {
@@ -360,15 +1698,19 @@ func (p *RobotsTxtPlugin) fetchFromURL(url string) (string, error) {
 			}
 			break
 		}
-		
+
 		if p.enableMetrics {
 			log.Printf("Successfully fetched %d bytes from %s", len(content), url)
 		}
-		
-		return string(content), nil
+
+		return urlFetchResult{
+			content:      string(content),
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+		}, nil
 	}
-	
-	return "", lastErr
+
+	return urlFetchResult{}, lastErr
 }
 
 // validateRobotsContent performs basic validation on robots.txt content
@@ -376,11 +1718,11 @@ func (p *RobotsTxtPlugin) validateRobotsContent(content string) error {
 	if len(content) == 0 {
 		return fmt.Errorf("content is empty")
 	}
-	
+
 	// Basic validation: check for common robots.txt patterns
 	lines := strings.Split(content, "\n")
 	hasUserAgent := false
-	
+
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if strings.HasPrefix(strings.ToLower(trimmed), "user-agent:") {
@@ -388,35 +1730,38 @@ func (p *RobotsTxtPlugin) validateRobotsContent(content string) error {
 			break
 		}
 	}
-	
+
 	if !hasUserAgent {
 		return fmt.Errorf("no User-agent directive found")
 	}
-	
+
 	return nil
 }
 
 // Metrics methods for monitoring
-func (p *RobotsTxtPlugin) incrementCacheHit() {
+func (p *RobotsTxtPlugin) incrementCacheHit(source string) {
 	if p.enableMetrics {
 		p.metricsMutex.Lock()
 		p.cacheHits++
+		p.sourceMetricsFor(source).cacheHits++
 		p.metricsMutex.Unlock()
 	}
 }
 
-func (p *RobotsTxtPlugin) incrementCacheMiss() {
+func (p *RobotsTxtPlugin) incrementCacheMiss(source string) {
 	if p.enableMetrics {
 		p.metricsMutex.Lock()
 		p.cacheMisses++
+		p.sourceMetricsFor(source).cacheMisses++
 		p.metricsMutex.Unlock()
 	}
 }
 
-func (p *RobotsTxtPlugin) incrementExternalCall() {
+func (p *RobotsTxtPlugin) incrementExternalCall(source string) {
 	if p.enableMetrics {
 		p.metricsMutex.Lock()
 		p.externalCalls++
+		p.sourceMetricsFor(source).externalCalls++
 		p.metricsMutex.Unlock()
 	}
 }
@@ -429,23 +1774,168 @@ func (p *RobotsTxtPlugin) incrementErrorCount() {
 	}
 }
 
+func (p *RobotsTxtPlugin) incrementBlockedCount(userAgent string) {
+	if p.enableMetrics {
+		p.metricsMutex.Lock()
+		p.blocked++
+		if _, tracked := p.blockedByAgent[userAgent]; tracked || len(p.blockedByAgent) < maxTrackedAgents {
+			p.blockedByAgent[userAgent]++
+		} else {
+			p.blockedOther++
+		}
+		p.metricsMutex.Unlock()
+	}
+}
+
+// incrementServedCount counts one successfully written /robots.txt response.
+func (p *RobotsTxtPlugin) incrementServedCount() {
+	if p.enableMetrics {
+		p.metricsMutex.Lock()
+		p.served++
+		p.metricsMutex.Unlock()
+	}
+}
+
+// incrementFetchResult counts one fetchAiRobotsTxtWithCache call outcome,
+// result being "success", "error", or "fallback".
+func (p *RobotsTxtPlugin) incrementFetchResult(result string) {
+	if !p.enableMetrics {
+		return
+	}
+	p.metricsMutex.Lock()
+	defer p.metricsMutex.Unlock()
+	switch result {
+	case "success":
+		p.fetchSuccess++
+	case "fallback":
+		p.fetchFallback++
+	default:
+		p.fetchError++
+	}
+}
+
+// observeFetchDuration records one fetchFromURL call's wall-clock duration
+// into the robotstxt_fetch_duration_seconds histogram.
+func (p *RobotsTxtPlugin) observeFetchDuration(seconds float64) {
+	if p.enableMetrics {
+		p.metricsMutex.Lock()
+		p.fetchDuration.observe(seconds)
+		p.metricsMutex.Unlock()
+	}
+}
+
+// sourceMetricsFor returns the per-source counters for source, creating them
+// on first use. Callers must hold metricsMutex.
+func (p *RobotsTxtPlugin) sourceMetricsFor(source string) *sourceMetrics {
+	m, ok := p.perSource[source]
+	if !ok {
+		m = &sourceMetrics{}
+		p.perSource[source] = m
+	}
+	return m
+}
+
 // GetMetrics returns current plugin metrics (useful for monitoring)
 func (p *RobotsTxtPlugin) GetMetrics() map[string]int64 {
 	if !p.enableMetrics {
 		return nil
 	}
-	
+
 	p.metricsMutex.RLock()
 	defer p.metricsMutex.RUnlock()
-	
+
 	return map[string]int64{
 		"cache_hits":     p.cacheHits,
 		"cache_misses":   p.cacheMisses,
 		"external_calls": p.externalCalls,
 		"errors":         p.errors,
+		"blocked":        p.blocked,
+		"served":         p.served,
 	}
 }
 
+// servePrometheusMetrics writes the current metrics in Prometheus text
+// exposition format. Traefik's Yaegi plugin sandbox forbids importing
+// prometheus/client_golang, so the format is rendered by hand.
+func (p *RobotsTxtPlugin) servePrometheusMetrics(rw http.ResponseWriter) {
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write([]byte(p.renderPrometheusMetrics()))
+}
+
+// renderPrometheusMetrics builds the Prometheus text exposition body.
+func (p *RobotsTxtPlugin) renderPrometheusMetrics() string {
+	p.metricsMutex.RLock()
+	defer p.metricsMutex.RUnlock()
+
+	var b strings.Builder
+
+	writeCounter := func(name, help string, value int64) {
+		b.WriteString("# HELP " + name + " " + help + "\n")
+		b.WriteString("# TYPE " + name + " counter\n")
+		b.WriteString(fmt.Sprintf("%s %d\n", name, value))
+	}
+
+	writeCounter("robotstxt_cache_hits_total", "Total number of cache hits across all sources.", p.cacheHits)
+	writeCounter("robotstxt_cache_misses_total", "Total number of cache misses across all sources.", p.cacheMisses)
+	writeCounter("robotstxt_external_calls_total", "Total number of external fetches across all sources.", p.externalCalls)
+	writeCounter("robotstxt_errors_total", "Total number of fetch/write errors.", p.errors)
+	writeCounter("robotstxt_served_total", "Total number of /robots.txt responses served.", p.served)
+
+	b.WriteString("# HELP robotstxt_fetch_total Total number of AI robots.txt fetch attempts, by result.\n")
+	b.WriteString("# TYPE robotstxt_fetch_total counter\n")
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_total{result=\"success\"} %d\n", p.fetchSuccess))
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_total{result=\"error\"} %d\n", p.fetchError))
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_total{result=\"fallback\"} %d\n", p.fetchFallback))
+
+	b.WriteString("# HELP robotstxt_blocked_total Total number of requests blocked by enforcement mode, by User-Agent.\n")
+	b.WriteString("# TYPE robotstxt_blocked_total counter\n")
+	if len(p.blockedByAgent) > 0 {
+		agents := make([]string, 0, len(p.blockedByAgent))
+		for agent := range p.blockedByAgent {
+			agents = append(agents, agent)
+		}
+		sort.Strings(agents)
+		for _, agent := range agents {
+			b.WriteString(fmt.Sprintf("robotstxt_blocked_total{user_agent=%q} %d\n", agent, p.blockedByAgent[agent]))
+		}
+	}
+	if p.blockedOther > 0 {
+		b.WriteString(fmt.Sprintf("robotstxt_blocked_total{user_agent=\"other\"} %d\n", p.blockedOther))
+	}
+
+	if len(p.perSource) > 0 {
+		sources := make([]string, 0, len(p.perSource))
+		for source := range p.perSource {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+
+		writePerSource := func(name, help string, value func(*sourceMetrics) int64) {
+			b.WriteString("# HELP " + name + " " + help + "\n")
+			b.WriteString("# TYPE " + name + " counter\n")
+			for _, source := range sources {
+				b.WriteString(fmt.Sprintf("%s{source=%q} %d\n", name, source, value(p.perSource[source])))
+			}
+		}
+
+		writePerSource("robotstxt_source_cache_hits_total", "Cache hits for this source.", func(m *sourceMetrics) int64 { return m.cacheHits })
+		writePerSource("robotstxt_source_cache_misses_total", "Cache misses for this source.", func(m *sourceMetrics) int64 { return m.cacheMisses })
+		writePerSource("robotstxt_source_external_calls_total", "External fetches for this source.", func(m *sourceMetrics) int64 { return m.externalCalls })
+	}
+
+	b.WriteString("# HELP robotstxt_fetch_duration_seconds Duration of upstream robots.txt fetches.\n")
+	b.WriteString("# TYPE robotstxt_fetch_duration_seconds histogram\n")
+	for i, bound := range p.fetchDuration.bounds {
+		b.WriteString(fmt.Sprintf("robotstxt_fetch_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), p.fetchDuration.counts[i]))
+	}
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_duration_seconds_bucket{le=\"+Inf\"} %d\n", p.fetchDuration.count))
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_duration_seconds_sum %s\n", strconv.FormatFloat(p.fetchDuration.sum, 'g', -1, 64)))
+	b.WriteString(fmt.Sprintf("robotstxt_fetch_duration_seconds_count %d\n", p.fetchDuration.count))
+
+	return b.String()
+}
+
 // Standard response writer methods (unchanged from original)
 func (r *responseWriter) WriteHeader(statusCode int) {
 	if !r.lastModified {
@@ -484,4 +1974,4 @@ func (r *responseWriter) Flush() {
 	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
-}
\ No newline at end of file
+}
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -56,7 +59,7 @@ func TestOverwriteOption(t *testing.T) {
 	cfg.Overwrite = true // This should remove the original content
 
 	ctx := context.Background()
-	
+
 	// Mock backend that returns existing robots.txt content
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
@@ -80,19 +83,19 @@ func TestOverwriteOption(t *testing.T) {
 	wantedRes := "# The following content was added on the fly by the Pangolin Robots.txt Traefik plugin: " +
 		"https://github.com/hhftechnology/traefik-pangolin-robots-txt\n" +
 		cfg.CustomRules
-	
+
 	actualBody := recorder.Body.String()
-	
+
 	// Verify original content is not present
 	if strings.Contains(actualBody, "/old-path/") {
 		t.Errorf("overwrite=true should remove original content, but found '/old-path/' in: %s", actualBody)
 	}
-	
+
 	// Verify our custom rules are present
 	if !strings.Contains(actualBody, "/admin/") {
 		t.Errorf("custom rules should be present, but '/admin/' not found in: %s", actualBody)
 	}
-	
+
 	if !bytes.Equal([]byte(wantedRes), recorder.Body.Bytes()) {
 		t.Errorf("got body %q, want %q", actualBody, wantedRes)
 	}
@@ -105,7 +108,7 @@ func TestOverwriteWithoutOriginalContent(t *testing.T) {
 	cfg.Overwrite = true
 
 	ctx := context.Background()
-	
+
 	// Mock backend that returns 404 (no robots.txt exists)
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusNotFound)
@@ -128,7 +131,7 @@ func TestOverwriteWithoutOriginalContent(t *testing.T) {
 	expectedContent := "# The following content was added on the fly by the Pangolin Robots.txt Traefik plugin: " +
 		"https://github.com/hhftechnology/traefik-pangolin-robots-txt\n" +
 		cfg.CustomRules
-	
+
 	if recorder.Body.String() != expectedContent {
 		t.Errorf("got body %q, want %q", recorder.Body.String(), expectedContent)
 	}
@@ -166,12 +169,12 @@ func TestAiRobotsTxtWithMockServer(t *testing.T) {
 	handler.ServeHTTP(recorder, req)
 
 	body := recorder.Body.String()
-	
+
 	// Should contain both the fetched AI content and custom rules
 	if !strings.Contains(body, "GPTBot") {
 		t.Errorf("should contain fetched AI robots content, but 'GPTBot' not found in: %s", body)
 	}
-	
+
 	if !strings.Contains(body, "/api/") {
 		t.Errorf("should contain custom rules, but '/api/' not found in: %s", body)
 	}
@@ -186,7 +189,7 @@ func TestAiRobotsTxtFromFile(t *testing.T) {
 	// Create a temporary file with robots.txt content
 	tempDir := t.TempDir()
 	robotsFile := filepath.Join(tempDir, "ai-robots.txt")
-	
+
 	fileContent := "User-agent: GoogleBot\nDisallow: /private/\n\nUser-agent: BingBot\nDisallow: /temp/\n"
 	if err := os.WriteFile(robotsFile, []byte(fileContent), 0644); err != nil {
 		t.Fatal(err)
@@ -214,25 +217,26 @@ func TestAiRobotsTxtFromFile(t *testing.T) {
 	handler.ServeHTTP(recorder, req)
 
 	body := recorder.Body.String()
-	
+
 	// Should contain content from the file
 	if !strings.Contains(body, "GoogleBot") {
 		t.Errorf("should contain file content, but 'GoogleBot' not found in: %s", body)
 	}
-	
+
 	if !strings.Contains(body, "/public/") {
 		t.Errorf("should contain custom rules, but '/public/' not found in: %s", body)
 	}
 }
 
-// TestCachingBehavior tests that the caching mechanism works correctly
+// TestCachingBehavior tests that the caching mechanism works correctly,
+// including the stale-while-revalidate background refresh loop.
 func TestCachingBehavior(t *testing.T) {
-	callCount := 0
+	var callCount int32
 	mockContent := "User-agent: TestBot\nDisallow: /cached/\n"
-	
+
 	// Mock server that counts requests
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
+		atomic.AddInt32(&callCount, 1)
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(mockContent))
 	}))
@@ -257,8 +261,8 @@ func TestCachingBehavior(t *testing.T) {
 	recorder1 := httptest.NewRecorder()
 	handler.ServeHTTP(recorder1, req1)
 
-	if callCount != 1 {
-		t.Errorf("expected 1 call to server, got %d", callCount)
+	if got := atomic.LoadInt32(&callCount); got != 1 {
+		t.Errorf("expected 1 call to server, got %d", got)
 	}
 
 	// Second request should use cache
@@ -266,35 +270,55 @@ func TestCachingBehavior(t *testing.T) {
 	recorder2 := httptest.NewRecorder()
 	handler.ServeHTTP(recorder2, req2)
 
-	if callCount != 1 {
-		t.Errorf("expected still 1 call to server (cached), got %d", callCount)
+	if got := atomic.LoadInt32(&callCount); got != 1 {
+		t.Errorf("expected still 1 call to server (cached), got %d", got)
 	}
 
-	// Wait for cache to expire and make another request
+	// Once the TTL passes, the background refresh loop (ticking at CacheTTL/2)
+	// keeps the cache warm without ever blocking a request: the last known
+	// good content is always served immediately while refreshes happen async.
 	time.Sleep(3 * time.Second)
+
+	start := time.Now()
 	req3, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
 	recorder3 := httptest.NewRecorder()
 	handler.ServeHTTP(recorder3, req3)
 
-	if callCount != 2 {
-		t.Errorf("expected 2 calls to server (cache expired), got %d", callCount)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request should be served from cache instantly, took %v", elapsed)
+	}
+
+	if !strings.Contains(recorder3.Body.String(), "TestBot") {
+		t.Errorf("expected cached content to still be served, got %q", recorder3.Body.String())
+	}
+
+	if got := atomic.LoadInt32(&callCount); got < 2 {
+		t.Errorf("expected background refresh to have hit the server at least once more, got %d calls", got)
 	}
 }
 
-// TestFallbackContent tests fallback mechanism when external source fails
-func TestFallbackContent(t *testing.T) {
-	// Mock server that always returns an error
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
+// TestMultipleSourcesMergeAndDedup tests that multiple AiRobotsTxtSources are
+// fetched and merged into a single deduplicated block per User-agent.
+func TestMultipleSourcesMergeAndDedup(t *testing.T) {
+	serverA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
 	}))
-	defer server.Close()
+	defer serverA.Close()
+
+	serverB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		// Overlaps with serverA's GPTBot rule and adds a new agent.
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n\nUser-agent: CCBot\nDisallow: /\n"))
+	}))
+	defer serverB.Close()
 
 	cfg := plugin.CreateConfig()
 	cfg.AiRobotsTxt = true
-	cfg.AiRobotsTxtURL = server.URL
-	cfg.FallbackContent = "User-agent: *\nDisallow: /fallback/\n"
-	cfg.CustomRules = "\nUser-agent: *\nDisallow: /custom/\n"
-	cfg.MaxRetries = 1 // Reduce retries for faster testing
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "community", URL: serverA.URL},
+		{Name: "corporate", URL: serverB.URL},
+	}
 
 	ctx := context.Background()
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
@@ -311,73 +335,50 @@ func TestFallbackContent(t *testing.T) {
 	}
 
 	handler.ServeHTTP(recorder, req)
-
 	body := recorder.Body.String()
-	
-	// Should contain fallback content since external source failed
-	if !strings.Contains(body, "/fallback/") {
-		t.Errorf("should contain fallback content, but '/fallback/' not found in: %s", body)
-	}
-	
-	// Should still contain custom rules
-	if !strings.Contains(body, "/custom/") {
-		t.Errorf("should contain custom rules, but '/custom/' not found in: %s", body)
+
+	if !strings.Contains(body, "# source: community, corporate") {
+		t.Errorf("expected merged source comment for GPTBot, got: %s", body)
 	}
-}
 
-// TestInvalidConfiguration tests that invalid configurations are rejected
-func TestInvalidConfiguration(t *testing.T) {
-	testCases := []struct {
-		name   string
-		config func() *plugin.Config
-	}{
-		{
-			name: "No options enabled",
-			config: func() *plugin.Config {
-				cfg := plugin.CreateConfig()
-				cfg.CustomRules = ""
-				cfg.AiRobotsTxt = false
-				return cfg
-			},
-		},
-		{
-			name: "Invalid file path",
-			config: func() *plugin.Config {
-				cfg := plugin.CreateConfig()
-				cfg.AiRobotsTxt = true
-				cfg.AiRobotsTxtPath = "relative/path/robots.txt" // Should be absolute
-				return cfg
-			},
-		},
+	if !strings.Contains(body, "User-agent: CCBot") {
+		t.Errorf("expected CCBot group from the second source, got: %s", body)
 	}
 
-	ctx := context.Background()
-	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	if count := strings.Count(body, "User-agent: GPTBot"); count != 1 {
+		t.Errorf("expected GPTBot to be merged into a single group, found %d", count)
+	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			_, err := plugin.New(ctx, next, tc.config(), "robots-txt-plugin")
-			if err == nil {
-				t.Errorf("expected error for test case %s, but got none", tc.name)
-			}
-		})
+	if count := strings.Count(body, "Disallow: /\n"); count != 2 {
+		t.Errorf("expected exactly 2 deduplicated Disallow lines (one per agent), got %d in: %s", count, body)
 	}
 }
 
-// TestNonRobotsTxtRequests ensures non-robots.txt requests pass through unchanged
-func TestNonRobotsTxtRequests(t *testing.T) {
+// TestMultipleSourcesPreservesSitemap tests that a Sitemap: line from one of
+// several merged AiRobotsTxtSources survives into the served /robots.txt,
+// matching internal/robotstxt.Merge's own guarantee at the integration level.
+func TestMultipleSourcesPreservesSitemap(t *testing.T) {
+	serverA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("Sitemap: https://a.example.com/sitemap.xml\nUser-agent: GPTBot\nDisallow: /\n"))
+	}))
+	defer serverA.Close()
+
+	serverB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: CCBot\nDisallow: /\n"))
+	}))
+	defer serverB.Close()
+
 	cfg := plugin.CreateConfig()
-	cfg.CustomRules = "\nUser-agent: *\nDisallow: /private/\n"
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "community", URL: serverA.URL},
+		{Name: "corporate", URL: serverB.URL},
+	}
 
 	ctx := context.Background()
-	
-	// Mock backend that should be called for non-robots.txt requests
-	backendCalled := false
-	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		backendCalled = true
-		rw.WriteHeader(http.StatusOK)
-		_, _ = rw.Write([]byte("This is not robots.txt"))
-	})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
 
 	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
 	if err != nil {
@@ -385,31 +386,104 @@ func TestNonRobotsTxtRequests(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/index.html", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
 
-	if !backendCalled {
-		t.Error("backend should be called for non-robots.txt requests")
+	if !strings.Contains(body, "Sitemap: https://a.example.com/sitemap.xml") {
+		t.Errorf("expected the Sitemap line to survive a multi-source merge, got: %s", body)
 	}
+}
 
-	if recorder.Body.String() != "This is not robots.txt" {
-		t.Errorf("non-robots.txt requests should pass through unchanged")
+// TestMultipleSourcesConditionalGetDoesNotChangeBody tests that once a
+// multi-source merge has cached content, a source answering with 304 Not
+// Modified on the next refresh leaves the merged body unchanged.
+func TestMultipleSourcesConditionalGetDoesNotChangeBody(t *testing.T) {
+	const etag = `"v1"`
+	var notModifiedRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModifiedRequests, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
+	}))
+	defer server.Close()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: CCBot\nDisallow: /\n"))
+	}))
+	defer other.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "community", URL: server.URL},
+		{Name: "corporate", URL: other.URL},
 	}
-}
+	cfg.CacheTTL = 1
 
-// TestAiRobotsTxt tests the original functionality with real GitHub URL
-func TestAiRobotsTxt(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping test that makes external HTTP request in short mode")
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	recorder1 := httptest.NewRecorder()
+	handler.ServeHTTP(recorder1, req)
+	firstBody := recorder1.Body.String()
+	if !strings.Contains(firstBody, "User-agent: GPTBot") {
+		t.Fatalf("expected initial fetch to succeed, got: %s", firstBody)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	recorder2 := httptest.NewRecorder()
+	handler.ServeHTTP(recorder2, req)
+	secondBody := recorder2.Body.String()
+
+	if secondBody != firstBody {
+		t.Errorf("expected a 304 response to leave the merged body unchanged, got: %s", secondBody)
+	}
+	if atomic.LoadInt32(&notModifiedRequests) == 0 {
+		t.Error("expected at least one conditional request to receive 304 Not Modified")
 	}
+}
+
+// TestMultipleSourcesOneFailingDoesNotBlankOthers tests that when one of
+// several AiRobotsTxtSources returns a server error, the other sources still
+// contribute to the merged output instead of the whole response going empty.
+func TestMultipleSourcesOneFailingDoesNotBlankOthers(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
+	}))
+	defer good.Close()
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
 
 	cfg := plugin.CreateConfig()
 	cfg.AiRobotsTxt = true
-	cfg.RequestTimeout = 30 // Longer timeout for real request
+	cfg.MaxRetries = 1
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "good", URL: good.URL},
+		{Name: "bad", URL: bad.URL},
+	}
 
 	ctx := context.Background()
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
@@ -420,41 +494,1000 @@ func TestAiRobotsTxt(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, "User-agent: GPTBot") {
+		t.Errorf("expected the good source to still contribute despite the bad source failing, got: %s", body)
+	}
+}
+
+// TestSourcePerTimeoutOverride tests that a SourceSpec.Timeout shorter than a
+// slow server's response time causes that source's fetch to fail while an
+// unrelated fast source is unaffected.
+func TestSourcePerTimeoutOverride(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: SlowBot\nDisallow: /\n"))
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: FastBot\nDisallow: /\n"))
+	}))
+	defer fast.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.MaxRetries = 1
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "slow", URL: slow.URL, Timeout: 1},
+		{Name: "fast", URL: fast.URL},
+	}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
 	handler.ServeHTTP(recorder, req)
-
 	body := recorder.Body.String()
-	
-	// Should contain some kind of robots.txt content (exact content may change)
-	if !strings.Contains(body, "User-agent:") && !strings.Contains(body, "Disallow:") {
-		t.Errorf("should contain robots.txt patterns, got: %s", body)
+
+	if !strings.Contains(body, "User-agent: FastBot") {
+		t.Errorf("expected the fast source to succeed, got: %s", body)
 	}
+}
 
-	if recorder.Code != http.StatusOK {
-		t.Errorf("got status code %d, want %d", recorder.Code, http.StatusOK)
+// TestMergeStrategyStrictOverride tests that a higher-Priority source wins a
+// shared agent outright under MergeStrategy "strict-override" instead of
+// being combined with the lower-priority one.
+func TestMergeStrategyStrictOverride(t *testing.T) {
+	community := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
+	}))
+	defer community.Close()
+
+	corporate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nAllow: /\n"))
+	}))
+	defer corporate.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.MergeStrategy = "strict-override"
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "community", URL: community.URL, Priority: 0},
+		{Name: "corporate", URL: corporate.URL, Priority: 10},
+	}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, "Allow: /") {
+		t.Errorf("expected the higher-priority source's Allow to win, got: %s", body)
+	}
+	if strings.Contains(body, "Disallow: /") {
+		t.Errorf("expected the lower-priority source's Disallow to be fully replaced, got: %s", body)
 	}
 }
 
-// TestNoOption tests the original validation error scenario
-func TestNoOption(t *testing.T) {
+// TestUnknownMergeStrategyRejected verifies that New returns an error for an
+// unrecognized MergeStrategy value.
+func TestUnknownMergeStrategyRejected(t *testing.T) {
 	cfg := plugin.CreateConfig()
-	cfg.CustomRules = ""
-	cfg.AiRobotsTxt = false
+	cfg.CustomRules = "User-agent: *\nDisallow: /\n"
+	cfg.MergeStrategy = "not-a-real-strategy"
 
 	ctx := context.Background()
 	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
 
 	_, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
 	if err == nil {
-		t.Fatal(errors.New("an error should be raised"))
-	} else {
-		errMsg := "set customRules or set aiRobotsTxt to true"
-		if err.Error() != errMsg {
-			t.Errorf("got err message %s, want %s", err.Error(), errMsg)
+		t.Fatal("expected an error for an unknown mergeStrategy")
+	}
+}
+
+// TestStrictParsingRejectsMalformedSource tests that a malformed source is
+// treated like a fetch error under StrictParsing, falling back to
+// FallbackContent the same way a network failure would.
+func TestStrictParsingRejectsMalformedSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("not a valid robots.txt directive line\n"))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.StrictParsing = true
+	cfg.FallbackContent = "User-agent: *\nDisallow: /fallback/\n"
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, "/fallback/") {
+		t.Errorf("expected malformed content under StrictParsing to fall back, got: %s", body)
+	}
+}
+
+// TestConditionalGetWithETag tests that a cached source is refetched with
+// If-None-Match once its TTL expires, and that a 304 response reuses the
+// previously cached content instead of replacing it.
+func TestConditionalGetWithETag(t *testing.T) {
+	var callCount int32
+	const mockContent = "User-agent: TestBot\nDisallow: /etag/\n"
+	const mockETag = `"mock-etag-v1"`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&callCount, 1)
+
+		if r.Header.Get("If-None-Match") == mockETag {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
+
+		w.Header().Set("ETag", mockETag)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(mockContent))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.CacheTTL = 1 // 1 second for quick expiry
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
 	}
-}
\ No newline at end of file
+
+	req1, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	recorder1 := httptest.NewRecorder()
+	handler.ServeHTTP(recorder1, req1)
+
+	if got := atomic.LoadInt32(&callCount); got != 1 {
+		t.Errorf("expected 1 call to server, got %d", got)
+	}
+	if !strings.Contains(recorder1.Body.String(), "Disallow: /etag/") {
+		t.Errorf("expected first response to contain fetched content, got %q", recorder1.Body.String())
+	}
+
+	// Let the cache TTL pass and the background refresh loop fire at least
+	// once: it should send If-None-Match and get back a 304.
+	time.Sleep(2 * time.Second)
+
+	if got := atomic.LoadInt32(&callCount); got < 2 {
+		t.Fatalf("expected background refresh to have sent at least one conditional request, got %d calls", got)
+	}
+
+	req2, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	recorder2 := httptest.NewRecorder()
+	handler.ServeHTTP(recorder2, req2)
+
+	if !strings.Contains(recorder2.Body.String(), "Disallow: /etag/") {
+		t.Errorf("expected content to survive a 304 response unchanged, got %q", recorder2.Body.String())
+	}
+}
+
+// TestStaleWhileRevalidate tests that an expired-but-not-yet-stale cache entry
+// is served immediately while a refresh happens in the background, so a slow
+// upstream never stalls the response.
+func TestStaleWhileRevalidate(t *testing.T) {
+	var callCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&callCount, 1)
+		if n > 1 {
+			// Slow enough that a synchronous fetch would be clearly visible
+			// in the test's elapsed time, but the background refresh should
+			// never block the request that triggered it.
+			time.Sleep(2 * time.Second)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: TestBot\nDisallow: /stale/\n"))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.CacheTTL = 1
+	cfg.StaleTTL = 30
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req1, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req1)
+
+	// Let the entry go past its TTL but stay within StaleTTL.
+	time.Sleep(1500 * time.Millisecond)
+
+	start := time.Now()
+	req2, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	recorder2 := httptest.NewRecorder()
+	handler.ServeHTTP(recorder2, req2)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("stale entry should be served immediately, took %v", elapsed)
+	}
+
+	if !strings.Contains(recorder2.Body.String(), "/stale/") {
+		t.Errorf("expected stale content to still be served, got %q", recorder2.Body.String())
+	}
+}
+
+// TestFallbackContent tests fallback mechanism when external source fails
+func TestFallbackContent(t *testing.T) {
+	// Mock server that always returns an error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.FallbackContent = "User-agent: *\nDisallow: /fallback/\n"
+	cfg.CustomRules = "\nUser-agent: *\nDisallow: /custom/\n"
+	cfg.MaxRetries = 1 // Reduce retries for faster testing
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(recorder, req)
+
+	body := recorder.Body.String()
+
+	// Should contain fallback content since external source failed
+	if !strings.Contains(body, "/fallback/") {
+		t.Errorf("should contain fallback content, but '/fallback/' not found in: %s", body)
+	}
+
+	// Should still contain custom rules
+	if !strings.Contains(body, "/custom/") {
+		t.Errorf("should contain custom rules, but '/custom/' not found in: %s", body)
+	}
+}
+
+// TestInvalidConfiguration tests that invalid configurations are rejected
+func TestInvalidConfiguration(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config func() *plugin.Config
+	}{
+		{
+			name: "No options enabled",
+			config: func() *plugin.Config {
+				cfg := plugin.CreateConfig()
+				cfg.CustomRules = ""
+				cfg.AiRobotsTxt = false
+				return cfg
+			},
+		},
+		{
+			name: "Invalid file path",
+			config: func() *plugin.Config {
+				cfg := plugin.CreateConfig()
+				cfg.AiRobotsTxt = true
+				cfg.AiRobotsTxtPath = "relative/path/robots.txt" // Should be absolute
+				return cfg
+			},
+		},
+	}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := plugin.New(ctx, next, tc.config(), "robots-txt-plugin")
+			if err == nil {
+				t.Errorf("expected error for test case %s, but got none", tc.name)
+			}
+		})
+	}
+}
+
+// TestNonRobotsTxtRequests ensures non-robots.txt requests pass through unchanged
+func TestNonRobotsTxtRequests(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "\nUser-agent: *\nDisallow: /private/\n"
+
+	ctx := context.Background()
+
+	// Mock backend that should be called for non-robots.txt requests
+	backendCalled := false
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		backendCalled = true
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("This is not robots.txt"))
+	})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(recorder, req)
+
+	if !backendCalled {
+		t.Error("backend should be called for non-robots.txt requests")
+	}
+
+	if recorder.Body.String() != "This is not robots.txt" {
+		t.Errorf("non-robots.txt requests should pass through unchanged")
+	}
+}
+
+// TestEnforceDisallowBlocksMatchingUserAgent tests that, with EnforceDisallow
+// enabled, a disallowed User-Agent is blocked on non-robots.txt requests while
+// unmatched agents and excluded paths still reach the backend.
+func TestEnforceDisallowBlocksMatchingUserAgent(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "\nUser-agent: GPTBot\nDisallow: /\n"
+	cfg.EnforceDisallow = true
+	cfg.EnforceExcludePaths = []string{"/healthz"}
+
+	ctx := context.Background()
+	backendCalled := false
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		backendCalled = true
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A blocked agent requesting a regular path should be short-circuited.
+	backendCalled = false
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+	req.Header.Set("User-Agent", "GPTBot/1.0")
+	handler.ServeHTTP(recorder, req)
+
+	if backendCalled {
+		t.Error("backend should not be called for a blocked User-Agent")
+	}
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected status %d for blocked request, got %d", http.StatusForbidden, recorder.Code)
+	}
+
+	// An unmatched agent should pass through.
+	backendCalled = false
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	handler.ServeHTTP(recorder, req)
+
+	if !backendCalled {
+		t.Error("backend should be called for a non-blocked User-Agent")
+	}
+
+	// An excluded path should pass through even for a blocked agent.
+	backendCalled = false
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/healthz", nil)
+	req.Header.Set("User-Agent", "GPTBot/1.0")
+	handler.ServeHTTP(recorder, req)
+
+	if !backendCalled {
+		t.Error("backend should be called for an excluded path regardless of User-Agent")
+	}
+}
+
+// TestEnforceDisallowReflectsFetchedSourceWithoutRefetching tests that
+// EnforceDisallow picks up a Disallow rule from an AiRobotsTxtURL source once
+// a /robots.txt request has fetched and cached it, and that a later proxied
+// request enforces it even after the source stops responding (i.e. it reads
+// the cached enforcement table rather than re-fetching on every request).
+func TestEnforceDisallowReflectsFetchedSourceWithoutRefetching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
+	}))
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.EnforceDisallow = true
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { rw.WriteHeader(http.StatusOK) })
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve /robots.txt once so the fetched source populates the enforcement
+	// table maintained for the top-level config.
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(recorder, req)
+
+	// Now take the source down: a subsequent proxied request must still be
+	// blocked from the cached enforcement table, not fail open because a
+	// fetch attempt errored.
+	server.Close()
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+	req.Header.Set("User-Agent", "GPTBot/1.0")
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected the cached enforcement table to still block GPTBot after the source went down, got status %d", recorder.Code)
+	}
+}
+
+// TestEnforceDisallowConcurrentColdRequestsDoNotEachFetch tests that, with
+// EnforceDisallow+AiRobotsTxt on, concurrent proxied (non-/robots.txt)
+// requests arriving immediately after New returns are served from the
+// enforcement table New already populated, instead of each independently
+// calling through to the upstream source on its own request goroutine.
+func TestEnforceDisallowConcurrentColdRequestsDoNotEachFetch(t *testing.T) {
+	var fetches int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&fetches, 1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: GPTBot\nDisallow: /\n"))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.EnforceDisallow = true
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { rw.WriteHeader(http.StatusOK) })
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atomic.LoadInt64(&fetches); got != 1 {
+		t.Fatalf("expected exactly one fetch from New populating the enforcement table, got %d", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+			req.Header.Set("User-Agent", "GPTBot/1.0")
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != http.StatusForbidden {
+				t.Errorf("expected GPTBot to be blocked, got status %d", recorder.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&fetches); got != 1 {
+		t.Errorf("expected the 5 concurrent cold proxied requests to reuse New's populated enforcement table without any extra fetch, got %d total fetches", got)
+	}
+}
+
+// TestEnforceDisallowBlockedUserAgentsExtra tests that BlockedUserAgentsExtra
+// blocks on every path, via both a literal substring and a "regex:" entry,
+// independently of what CustomRules disallows.
+func TestEnforceDisallowBlockedUserAgentsExtra(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "\nUser-agent: GPTBot\nDisallow: /\n"
+	cfg.EnforceDisallow = true
+	cfg.BlockedUserAgentsExtra = []string{"badbot", `regex:^evil-.*-crawler$`}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ua := range []string{"BadBot/2.0", "evil-scrapy-crawler"} {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+		req.Header.Set("User-Agent", ua)
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("User-Agent %q: expected status %d, got %d", ua, http.StatusForbidden, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), "blocked") {
+			t.Errorf("User-Agent %q: expected a text body explaining the block, got: %s", ua, recorder.Body.String())
+		}
+	}
+
+	// An agent matching neither CustomRules nor BlockedUserAgentsExtra passes through.
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected unmatched User-Agent to pass through, got status %d", recorder.Code)
+	}
+}
+
+// TestMetricsEndpoint tests that MetricsPath is intercepted with
+// Prometheus text-format counters reflecting prior requests, and that the
+// backend is never reached for that path.
+func TestMetricsEndpoint(t *testing.T) {
+	mockContent := "User-agent: TestBot\nDisallow: /metrics-test/\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(mockContent))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = server.URL
+	cfg.EnableMetrics = true
+	cfg.MetricsPath = "/robots-metrics"
+
+	ctx := context.Background()
+	backendCalled := false
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		backendCalled = true
+	})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One request to /robots.txt generates a cache miss / external call.
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	backendCalled = false
+
+	metricsReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots-metrics", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, metricsReq)
+
+	if backendCalled {
+		t.Error("backend should not be called for the metrics path")
+	}
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "robotstxt_cache_misses_total 1") {
+		t.Errorf("expected a cache miss counted, got: %s", body)
+	}
+	if !strings.Contains(body, "robotstxt_external_calls_total 1") {
+		t.Errorf("expected an external call counted, got: %s", body)
+	}
+	if !strings.Contains(body, "robotstxt_fetch_duration_seconds_count 1") {
+		t.Errorf("expected a fetch duration observation, got: %s", body)
+	}
+	if !strings.Contains(body, `robotstxt_source_external_calls_total{source="`+server.URL+`"} 1`) {
+		t.Errorf("expected a per-source external calls label, got: %s", body)
+	}
+	if !strings.Contains(body, `robotstxt_fetch_total{result="success"} 1`) {
+		t.Errorf("expected a successful fetch counted, got: %s", body)
+	}
+	if !strings.Contains(body, "robotstxt_served_total 1") {
+		t.Errorf("expected the /robots.txt response to be counted as served, got: %s", body)
+	}
+}
+
+// TestMetricsEndpointBlockedByUserAgent tests that blocked requests are
+// reflected in robotstxt_blocked_total, labeled per User-Agent.
+func TestMetricsEndpointBlockedByUserAgent(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "\nUser-agent: GPTBot\nDisallow: /\n"
+	cfg.EnforceDisallow = true
+	cfg.EnableMetrics = true
+	cfg.MetricsPath = "/robots-metrics"
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockedReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+	blockedReq.Header.Set("User-Agent", "GPTBot/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), blockedReq)
+
+	metricsReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots-metrics", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, metricsReq)
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, `robotstxt_blocked_total{user_agent="GPTBot/1.0"} 1`) {
+		t.Errorf("expected a per-User-Agent blocked counter, got: %s", body)
+	}
+}
+
+// TestMetricsEndpointBlockedByUserAgentCapsCardinality tests that blocked
+// requests from more distinct User-Agents than the tracked cap fold into a
+// single user_agent="other" bucket instead of growing the per-agent label
+// set without bound.
+func TestMetricsEndpointBlockedByUserAgentCapsCardinality(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "\nUser-agent: *\nDisallow: /\n"
+	cfg.EnforceDisallow = true
+	cfg.EnableMetrics = true
+	cfg.MetricsPath = "/robots-metrics"
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const distinctAgents = 60
+	for i := 0; i < distinctAgents; i++ {
+		blockedReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/some/page", nil)
+		blockedReq.Header.Set("User-Agent", fmt.Sprintf("ScraperBot/%d", i))
+		handler.ServeHTTP(httptest.NewRecorder(), blockedReq)
+	}
+
+	metricsReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots-metrics", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, metricsReq)
+
+	body := recorder.Body.String()
+	labelCount := strings.Count(body, `robotstxt_blocked_total{user_agent="ScraperBot/`)
+	if labelCount >= distinctAgents {
+		t.Errorf("expected per-agent labels to be capped well below %d distinct agents, got %d labels: %s", distinctAgents, labelCount, body)
+	}
+	if !strings.Contains(body, `robotstxt_blocked_total{user_agent="other"}`) {
+		t.Errorf("expected the agents past the cap to be folded into user_agent=\"other\", got: %s", body)
+	}
+}
+
+// TestPresetsRenderAndMergeWithSources tests that bundled presets render a
+// "# preset:" header and merge alongside a regular fetched source.
+func TestPresetsRenderAndMergeWithSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("User-agent: CustomBot\nDisallow: /\n"))
+	}))
+	defer server.Close()
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtSources = []plugin.SourceSpec{
+		{Name: "custom", URL: server.URL},
+	}
+	cfg.Presets = []string{"ai-training"}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, "# preset: ai-training (v2024-11)") {
+		t.Errorf("expected a preset header, got: %s", body)
+	}
+	if !strings.Contains(body, "# source: custom") {
+		t.Errorf("expected a source header alongside the preset, got: %s", body)
+	}
+	if !strings.Contains(body, "User-agent: CustomBot") {
+		t.Errorf("expected the fetched source's group, got: %s", body)
+	}
+	if !strings.Contains(body, "User-agent: GPTBot") {
+		t.Errorf("expected the ai-training preset's GPTBot group, got: %s", body)
+	}
+}
+
+// TestPresetsOnlyConfigDoesNotFetchDefaultSource verifies that a presets-only
+// configuration (AiRobotsTxt left false) never falls back to the default
+// GitHub ai.robots.txt URL and still serves the preset content.
+func TestPresetsOnlyConfigDoesNotFetchDefaultSource(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.Presets = []string{"seo-scrapers"}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(recorder, req)
+	body := recorder.Body.String()
+
+	if !strings.Contains(body, "# preset: seo-scrapers (v2024-11)") {
+		t.Errorf("expected the seo-scrapers preset header, got: %s", body)
+	}
+	if !strings.Contains(body, "User-agent: AhrefsBot") {
+		t.Errorf("expected the seo-scrapers preset's AhrefsBot group, got: %s", body)
+	}
+}
+
+// TestUnknownPresetRejected verifies that New returns an error naming the
+// available presets when an unknown preset is configured.
+func TestUnknownPresetRejected(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.Presets = []string{"not-a-real-preset"}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err == nil {
+		t.Fatal("expected an error for an unknown preset")
+	}
+	if !strings.Contains(err.Error(), "unknown preset") {
+		t.Errorf("expected an unknown preset error, got: %v", err)
+	}
+}
+
+// TestAiRobotsTxt tests the original functionality with real GitHub URL
+func TestAiRobotsTxt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that makes external HTTP request in short mode")
+	}
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.RequestTimeout = 30 // Longer timeout for real request
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/robots.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(recorder, req)
+
+	body := recorder.Body.String()
+
+	// Should contain some kind of robots.txt content (exact content may change)
+	if !strings.Contains(body, "User-agent:") && !strings.Contains(body, "Disallow:") {
+		t.Errorf("should contain robots.txt patterns, got: %s", body)
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("got status code %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
+
+// TestNoOption tests the original validation error scenario
+func TestNoOption(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = ""
+	cfg.AiRobotsTxt = false
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err == nil {
+		t.Fatal(errors.New("an error should be raised"))
+	} else {
+		errMsg := "set customRules, set aiRobotsTxt to true, or set presets"
+		if err.Error() != errMsg {
+			t.Errorf("got err message %s, want %s", err.Error(), errMsg)
+		}
+	}
+}
+
+// TestHostsOverrideProduceDifferentBodies tests that requests to two
+// different hosts, each with its own Hosts entry, are served different
+// CustomRules/AiRobotsTxt content from the same handler instance, and that
+// an unmatched host falls back to the top-level Config.
+func TestHostsOverrideProduceDifferentBodies(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "User-agent: *\nDisallow: /default-only/\n"
+	cfg.Hosts = map[string]plugin.HostConfig{
+		"a.example.com": {CustomRules: "User-agent: *\nDisallow: /a-only/\n"},
+		"b.example.com": {CustomRules: "User-agent: *\nDisallow: /b-only/\n"},
+	}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetch := func(host string) string {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/robots.txt", nil)
+		handler.ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+
+	bodyA := fetch("a.example.com")
+	bodyB := fetch("b.example.com")
+	bodyDefault := fetch("unmatched.example.com")
+
+	if !strings.Contains(bodyA, "/a-only/") || strings.Contains(bodyA, "/b-only/") || strings.Contains(bodyA, "/default-only/") {
+		t.Errorf("expected a.example.com to only see its own CustomRules, got: %s", bodyA)
+	}
+	if !strings.Contains(bodyB, "/b-only/") || strings.Contains(bodyB, "/a-only/") {
+		t.Errorf("expected b.example.com to only see its own CustomRules, got: %s", bodyB)
+	}
+	if !strings.Contains(bodyDefault, "/default-only/") {
+		t.Errorf("expected an unmatched host to fall back to the top-level Config, got: %s", bodyDefault)
+	}
+	if bodyA == bodyB {
+		t.Error("expected a.example.com and b.example.com to produce different bodies")
+	}
+}
+
+// TestHostsWildcardMatchAndLongestMatchWins tests that a "*.example.com"
+// entry matches any subdomain, and that a more specific exact hostname entry
+// takes precedence over a wildcard that would also match it.
+func TestHostsWildcardMatchAndLongestMatchWins(t *testing.T) {
+	cfg := plugin.CreateConfig()
+	cfg.CustomRules = "User-agent: *\nDisallow: /default-only/\n"
+	cfg.Hosts = map[string]plugin.HostConfig{
+		"*.example.com": {CustomRules: "User-agent: *\nDisallow: /wildcard/\n"},
+		"a.example.com": {CustomRules: "User-agent: *\nDisallow: /exact/\n"},
+	}
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetch := func(host string) string {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/robots.txt", nil)
+		handler.ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+
+	if body := fetch("a.example.com"); !strings.Contains(body, "/exact/") {
+		t.Errorf("expected the exact hostname entry to win over the wildcard, got: %s", body)
+	}
+	if body := fetch("c.example.com"); !strings.Contains(body, "/wildcard/") {
+		t.Errorf("expected an unlisted subdomain to match the wildcard entry, got: %s", body)
+	}
+	if body := fetch("example.com"); !strings.Contains(body, "/default-only/") {
+		t.Errorf("expected the bare domain to not match \"*.example.com\" and fall back to the default, got: %s", body)
+	}
+}
+
+// TestFileWatchHotReload tests that a local AiRobotsTxtPath source is
+// re-read and served within FileWatchInterval after its file changes on
+// disk, without waiting for CacheTTL to expire.
+func TestFileWatchHotReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "robots.txt")
+	if err := os.WriteFile(path, []byte("User-agent: GPTBot\nDisallow: /\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := plugin.CreateConfig()
+	cfg.AiRobotsTxt = true
+	cfg.AiRobotsTxtURL = ""
+	cfg.AiRobotsTxtPath = path
+	cfg.CacheTTL = 300
+	cfg.FileWatchInterval = 1
+
+	ctx := context.Background()
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := plugin.New(ctx, next, cfg, "robots-txt-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetch := func() string {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/robots.txt", nil)
+		handler.ServeHTTP(recorder, req)
+		return recorder.Body.String()
+	}
+
+	if body := fetch(); !strings.Contains(body, "GPTBot") {
+		t.Fatalf("expected initial content to be served, got: %s", body)
+	}
+
+	if err := os.WriteFile(path, []byte("User-agent: CCBot\nDisallow: /\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+
+	if body := fetch(); !strings.Contains(body, "CCBot") || strings.Contains(body, "GPTBot") {
+		t.Errorf("expected updated content to be served after the file changed, got: %s", body)
+	}
+}